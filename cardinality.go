@@ -0,0 +1,207 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// OverflowTagValue replaces every tag value of a series created after its
+// Scope or measurement hit a cardinality limit.
+const OverflowTagValue = "__overflow__"
+
+// CardinalityLimits bounds how many distinct tagged series a Scope creates.
+// Once a limit is hit, lookups of new tag combinations, through methods like
+// Meter, IntVal or FuncNamed, return a shared series whose tag values are all
+// OverflowTagValue instead of creating new ones. Series created without tags
+// are never limited. Zero values mean no limit.
+type CardinalityLimits struct {
+	// Scope bounds the tagged series across the whole Scope.
+	Scope int
+
+	// Measurement bounds the tagged series per measurement name.
+	Measurement int
+
+	// Measurements overrides Measurement for specific measurement names.
+	// Funcs are limited by "func:" followed by their name. The edges of all
+	// Funcs using FuncStats.UseEdges are limited together by
+	// "function_edge", and don't count toward the Scope limit.
+	Measurements map[string]int
+}
+
+// TagCardinality is the number of distinct values of a tag key.
+type TagCardinality struct {
+	Key    string
+	Values int
+}
+
+// CardinalityReport describes a measurement that hit a cardinality limit.
+type CardinalityReport struct {
+	Scope       string
+	Measurement string
+
+	// Series is the number of tagged series for the measurement, not counting
+	// the overflow series.
+	Series int
+
+	// Limit is the limit for the measurement, or 0 if only the Scope limit
+	// applies.
+	Limit int
+
+	// Overflowed counts lookups sent to the overflow series.
+	Overflowed int64
+
+	// TagKeys lists tag keys by decreasing number of distinct values.
+	TagKeys []TagCardinality
+}
+
+type scopeLimits struct {
+	limits     CardinalityLimits
+	total      int
+	series     map[string]int
+	overflowed map[string]*int64 // sync/atomic values
+}
+
+func (l *scopeLimits) measurementLimit(measurement string) int {
+	if limit, ok := l.limits.Measurements[measurement]; ok {
+		return limit
+	}
+	return l.limits.Measurement
+}
+
+func (l *scopeLimits) added(meta *sourceMeta) {
+	if len(meta.tags) == 0 || meta.overflow {
+		return
+	}
+	if l.series == nil {
+		l.series = map[string]int{}
+	}
+	l.total++
+	l.series[meta.measurement]++
+}
+
+func (l *scopeLimits) removed(meta *sourceMeta) {
+	if len(meta.tags) == 0 || meta.overflow {
+		return
+	}
+	l.total--
+	if l.series[meta.measurement]--; l.series[meta.measurement] <= 0 {
+		delete(l.series, meta.measurement)
+	}
+}
+
+// overflow counts a lookup sent to the overflow series of measurement. It
+// requires the Scope's write lock, as it may add the measurement's counter.
+func (l *scopeLimits) overflow(measurement string) {
+	counter, ok := l.overflowed[measurement]
+	if !ok {
+		counter = new(int64)
+		if l.overflowed == nil {
+			l.overflowed = map[string]*int64{}
+		}
+		l.overflowed[measurement] = counter
+	}
+	atomic.AddInt64(counter, 1)
+}
+
+func overflowTags(tags []SeriesTag) []SeriesTag {
+	rv := make([]SeriesTag, 0, len(tags))
+	for _, tag := range tags {
+		rv = append(rv, NewSeriesTag(tag.Key, OverflowTagValue))
+	}
+	return rv
+}
+
+// SetCardinalityLimits configures the cardinality limits of the Scope.
+// Existing series are kept, even if they exceed the new limits.
+func (s *Scope) SetCardinalityLimits(limits CardinalityLimits) {
+	s.mtx.Lock()
+	s.limits.limits = limits
+	s.mtx.Unlock()
+	s.edges.setLimit(limits)
+}
+
+func (s *Scope) overLimitLocked(measurement string) bool {
+	l := &s.limits
+	if limit := l.measurementLimit(measurement); limit > 0 &&
+		l.series[measurement] >= limit {
+		return true
+	}
+	return l.limits.Scope > 0 && l.total >= l.limits.Scope
+}
+
+// CardinalityReports returns a report for every measurement of the Scope
+// that has hit a cardinality limit, sorted by measurement. The same reports
+// are included in the Scope's Stats as the "cardinality_limit" measurement.
+func (s *Scope) CardinalityReports() (reports []CardinalityReport) {
+	if overflowed := atomic.LoadInt64(&s.edges.overflowed); overflowed > 0 {
+		reports = append(reports, CardinalityReport{
+			Scope:       s.name,
+			Measurement: edgeMeasurement,
+			Series:      int(atomic.LoadInt64(&s.edges.series)),
+			Limit:       int(atomic.LoadInt64(&s.edges.limit)),
+			Overflowed:  overflowed,
+		})
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if len(s.limits.overflowed) == 0 {
+		return reports
+	}
+
+	values := make(map[string]map[string]map[string]struct{}, len(s.limits.overflowed))
+	for measurement := range s.limits.overflowed {
+		values[measurement] = map[string]map[string]struct{}{}
+	}
+	for _, meta := range s.meta {
+		keys, ok := values[meta.measurement]
+		if !ok || meta.overflow {
+			continue
+		}
+		for _, tag := range meta.tags {
+			if keys[tag.Key] == nil {
+				keys[tag.Key] = map[string]struct{}{}
+			}
+			keys[tag.Key][tag.Val] = struct{}{}
+		}
+	}
+
+	for measurement, overflowed := range s.limits.overflowed {
+		report := CardinalityReport{
+			Scope:       s.name,
+			Measurement: measurement,
+			Series:      s.limits.series[measurement],
+			Limit:       s.limits.measurementLimit(measurement),
+			Overflowed:  atomic.LoadInt64(overflowed),
+		}
+		for key, vals := range values[measurement] {
+			report.TagKeys = append(report.TagKeys, TagCardinality{Key: key, Values: len(vals)})
+		}
+		sort.Slice(report.TagKeys, func(i, j int) bool {
+			a, b := report.TagKeys[i], report.TagKeys[j]
+			if a.Values != b.Values {
+				return a.Values > b.Values
+			}
+			return a.Key < b.Key
+		})
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Measurement < reports[j].Measurement
+	})
+	return reports
+}
@@ -55,6 +55,19 @@ func storeTraceWatcherRef(addr **traceWatcherRef, val *traceWatcherRef) {
 	bigHonkinMutex.Unlock()
 }
 
+func loadSamplerRef(addr **samplerRef) (val *samplerRef) {
+	bigHonkinMutex.Lock()
+	val = *addr
+	bigHonkinMutex.Unlock()
+	return val
+}
+
+func storeSamplerRef(addr **samplerRef, val *samplerRef) {
+	bigHonkinMutex.Lock()
+	*addr = val
+	bigHonkinMutex.Unlock()
+}
+
 func compareAndSwapSpanObserverTuple(addr **spanObserverTuple,
 	old, new *spanObserverTuple) bool {
 	bigHonkinMutex.Lock()
@@ -53,6 +53,21 @@ func storeTraceWatcherRef(addr **traceWatcherRef, val *traceWatcherRef) {
 		unsafe.Pointer(val))
 }
 
+//
+// *samplerRef atomic functions
+//
+
+func loadSamplerRef(addr **samplerRef) (val *samplerRef) {
+	return (*samplerRef)(atomic.LoadPointer(
+		(*unsafe.Pointer)(unsafe.Pointer(addr))))
+}
+
+func storeSamplerRef(addr **samplerRef, val *samplerRef) {
+	atomic.StorePointer(
+		(*unsafe.Pointer)(unsafe.Pointer(addr)),
+		unsafe.Pointer(val))
+}
+
 //
 // *spanObserverTuple atomic functons
 //
@@ -0,0 +1,400 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collect
+
+import (
+	"sync"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+// FlightRecorderOptions configures a FlightRecorder. Zero values get
+// defaults.
+type FlightRecorderOptions struct {
+	// Recent is the number of most recently finished traces to keep.
+	// Defaults to 64.
+	Recent int
+
+	// Errored and Panicked are the number of most recent traces with an
+	// errored or panicked span to keep, in addition to Recent. Both default
+	// to 16.
+	Errored  int
+	Panicked int
+
+	// MaxTraces bounds the number of unfinished traces buffered at once.
+	// New traces beyond this are dropped. Defaults to 1000.
+	MaxTraces int
+
+	// MaxSpansPerTrace bounds the number of spans buffered per trace. Later
+	// spans are dropped. Defaults to 1000.
+	MaxSpansPerTrace int
+}
+
+// RecordedTrace is a finished trace kept by a FlightRecorder. It is shared
+// by every caller, so it must not be modified, and Spans must be copied
+// before sorting it.
+type RecordedTrace struct {
+	TraceId  int64
+	Root     *FinishedSpan
+	Spans    []*FinishedSpan
+	Start    time.Time
+	Finish   time.Time
+	Errored  bool
+	Panicked bool
+}
+
+// Duration returns the time from the first span start to the last span
+// finish.
+func (t *RecordedTrace) Duration() time.Duration { return t.Finish.Sub(t.Start) }
+
+// FlightRecorder implements the SpanObserver interface. It buffers the spans
+// of every trace it observes and, once a trace has no more running spans,
+// keeps it in bounded, in-memory slots: the most recent traces, the most
+// recent errored and panicked traces, and for every Func, the trace with its
+// slowest span. Kept traces can be looked at after the fact, for instance at
+// /traces/recent in the present package. See StartFlightRecorder.
+type FlightRecorder struct {
+	opts FlightRecorderOptions
+
+	mtx      sync.Mutex
+	building map[*monkit.Trace]*buildingTrace
+	recent   []*RecordedTrace
+	errored  []*RecordedTrace
+	panicked []*RecordedTrace
+	slowest  map[*monkit.Func]slowestTrace
+	recorded int64
+	dropped  int64
+}
+
+// buildingTrace is a trace being buffered by a FlightRecorder.
+type buildingTrace struct {
+	running int
+	spans   []*FinishedSpan
+}
+
+type slowestTrace struct {
+	duration time.Duration
+	trace    *RecordedTrace
+}
+
+// NewFlightRecorder creates a FlightRecorder. It must be registered with
+// traces, usually with ObserveAllTraces, to record anything.
+func NewFlightRecorder(opts FlightRecorderOptions) *FlightRecorder {
+	if opts.Recent <= 0 {
+		opts.Recent = 64
+	}
+	if opts.Errored <= 0 {
+		opts.Errored = 16
+	}
+	if opts.Panicked <= 0 {
+		opts.Panicked = 16
+	}
+	if opts.MaxTraces <= 0 {
+		opts.MaxTraces = 1000
+	}
+	if opts.MaxSpansPerTrace <= 0 {
+		opts.MaxSpansPerTrace = 1000
+	}
+	return &FlightRecorder{
+		opts:     opts,
+		building: map[*monkit.Trace]*buildingTrace{},
+		slowest:  map[*monkit.Func]slowestTrace{},
+	}
+}
+
+var (
+	recordersMtx sync.Mutex
+	recorders    = map[*monkit.Registry]*FlightRecorder{}
+)
+
+// StartFlightRecorder creates a FlightRecorder observing all traces of the
+// Registry, and makes it the one FlightRecorderFor returns for the
+// Registry, replacing any started before. stop unregisters it. Unlike with
+// ObserveAllTraces, the FlightRecorder stops observing each trace once it is
+// recorded.
+func StartFlightRecorder(r *monkit.Registry, opts FlightRecorderOptions) (
+	fr *FlightRecorder, stop func()) {
+	fr = NewFlightRecorder(opts)
+	cancel := fr.observeAllTraces(r)
+	recordersMtx.Lock()
+	recorders[r] = fr
+	recordersMtx.Unlock()
+	return fr, func() {
+		cancel()
+		recordersMtx.Lock()
+		if recorders[r] == fr {
+			delete(recorders, r)
+		}
+		recordersMtx.Unlock()
+	}
+}
+
+// FlightRecorderFor returns the FlightRecorder started for the Registry with
+// StartFlightRecorder, or nil. Registries returned by WithTransformers are
+// distinct from the Registry they were made from.
+func FlightRecorderFor(r *monkit.Registry) *FlightRecorder {
+	recordersMtx.Lock()
+	defer recordersMtx.Unlock()
+	return recorders[r]
+}
+
+// observeAllTraces registers fr with all traces present and future on r,
+// like ObserveAllTraces, but stops observing each trace once fr no longer
+// buffers it.
+func (fr *FlightRecorder) observeAllTraces(r *monkit.Registry) (cancel func()) {
+	var mtx sync.Mutex
+	var stopping bool
+	cancelers := map[*monkit.Trace]func(){}
+
+	observe := func(t *monkit.Trace) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if _, exists := cancelers[t]; exists || stopping {
+			return
+		}
+		cancelers[t] = t.ObserveSpans(recorderObserver{fr: fr, detach: func() {
+			mtx.Lock()
+			defer mtx.Unlock()
+			if canceler, exists := cancelers[t]; exists && !stopping {
+				delete(cancelers, t)
+				canceler()
+			}
+		}})
+	}
+
+	mainCanceler := r.ObserveTraces(observe)
+
+	// pick up live traces we can find
+	r.RootSpans(func(s *monkit.Span) { observe(s.Trace()) })
+
+	return func() {
+		mainCanceler()
+		mtx.Lock()
+		defer mtx.Unlock()
+		stopping = true
+		for _, canceler := range cancelers {
+			canceler()
+		}
+	}
+}
+
+// recorderObserver passes spans on to fr, and calls detach once fr no
+// longer buffers the trace.
+type recorderObserver struct {
+	fr     *FlightRecorder
+	detach func()
+}
+
+func (o recorderObserver) Start(s *monkit.Span) { o.fr.Start(s) }
+
+func (o recorderObserver) Finish(s *monkit.Span, err error, panicked bool,
+	finish time.Time) {
+	if !o.fr.finish(s, err, panicked, finish) {
+		o.detach()
+	}
+}
+
+// Start is to implement the monkit.SpanObserver interface. Traces are only
+// buffered if their first span is seen, so traces joined partway through,
+// or refused because MaxTraces were already buffered, are dropped whole.
+func (fr *FlightRecorder) Start(s *monkit.Span) {
+	t := s.Trace()
+	fr.mtx.Lock()
+	defer fr.mtx.Unlock()
+	bt := fr.building[t]
+	if bt == nil {
+		if t.Spans() != 1 || len(fr.building) >= fr.opts.MaxTraces {
+			return
+		}
+		bt = &buildingTrace{}
+		fr.building[t] = bt
+	}
+	bt.running++
+}
+
+// Finish is to implement the monkit.SpanObserver interface. When the last
+// running span of a trace finishes, the trace is recorded.
+func (fr *FlightRecorder) Finish(s *monkit.Span, err error, panicked bool,
+	finish time.Time) {
+	fr.finish(s, err, panicked, finish)
+}
+
+// finish buffers the finished span, recording its trace if it was the last
+// running span, and returns whether the trace is still buffered.
+func (fr *FlightRecorder) finish(s *monkit.Span, err error, panicked bool,
+	finish time.Time) (buffered bool) {
+	t := s.Trace()
+	fr.mtx.Lock()
+	defer fr.mtx.Unlock()
+	bt := fr.building[t]
+	if bt == nil {
+		fr.dropped++
+		return false
+	}
+	if len(bt.spans) < fr.opts.MaxSpansPerTrace {
+		bt.spans = append(bt.spans,
+			&FinishedSpan{Span: s, Err: err, Panicked: panicked, Finish: finish})
+	} else {
+		fr.dropped++
+	}
+	if bt.running--; bt.running > 0 {
+		return true
+	}
+	delete(fr.building, t)
+	fr.recordLocked(newRecordedTrace(t, bt.spans))
+	return false
+}
+
+func newRecordedTrace(t *monkit.Trace, spans []*FinishedSpan) *RecordedTrace {
+	StartTimeSorter(spans).Sort()
+	rt := &RecordedTrace{TraceId: t.Id(), Spans: spans}
+	ids := make(map[int64]bool, len(spans))
+	for _, fs := range spans {
+		ids[fs.Span.Id()] = true
+	}
+	for _, fs := range spans {
+		if parentId, ok := fs.Span.ParentId(); rt.Root == nil && (!ok || !ids[parentId]) {
+			rt.Root = fs
+		}
+		if start := fs.Span.Start(); rt.Start.IsZero() || start.Before(rt.Start) {
+			rt.Start = start
+		}
+		if fs.Finish.After(rt.Finish) {
+			rt.Finish = fs.Finish
+		}
+		rt.Errored = rt.Errored || (fs.Err != nil && !fs.Panicked)
+		rt.Panicked = rt.Panicked || fs.Panicked
+	}
+	return rt
+}
+
+func (fr *FlightRecorder) recordLocked(rt *RecordedTrace) {
+	fr.recorded++
+	fr.recent = pushTrace(fr.recent, rt, fr.opts.Recent)
+	if rt.Errored {
+		fr.errored = pushTrace(fr.errored, rt, fr.opts.Errored)
+	}
+	if rt.Panicked {
+		fr.panicked = pushTrace(fr.panicked, rt, fr.opts.Panicked)
+	}
+	for _, fs := range rt.Spans {
+		f := fs.Span.Func()
+		duration := fs.Finish.Sub(fs.Span.Start())
+		if slowest, exists := fr.slowest[f]; !exists || duration > slowest.duration {
+			fr.slowest[f] = slowestTrace{duration: duration, trace: rt}
+		}
+	}
+}
+
+// pushTrace appends rt to traces, dropping the oldest trace if there would
+// be more than max.
+func pushTrace(traces []*RecordedTrace, rt *RecordedTrace, max int) []*RecordedTrace {
+	if len(traces) >= max {
+		copy(traces, traces[1:])
+		traces = traces[:len(traces)-1]
+	}
+	return append(traces, rt)
+}
+
+func newestFirst(traces []*RecordedTrace) []*RecordedTrace {
+	rv := make([]*RecordedTrace, 0, len(traces))
+	for i := len(traces) - 1; i >= 0; i-- {
+		rv = append(rv, traces[i])
+	}
+	return rv
+}
+
+// Recent returns the most recently finished traces, newest first.
+func (fr *FlightRecorder) Recent() []*RecordedTrace {
+	fr.mtx.Lock()
+	defer fr.mtx.Unlock()
+	return newestFirst(fr.recent)
+}
+
+// Errored returns the most recent traces with a span that returned an
+// error, newest first.
+func (fr *FlightRecorder) Errored() []*RecordedTrace {
+	fr.mtx.Lock()
+	defer fr.mtx.Unlock()
+	return newestFirst(fr.errored)
+}
+
+// Panicked returns the most recent traces with a span that panicked, newest
+// first.
+func (fr *FlightRecorder) Panicked() []*RecordedTrace {
+	fr.mtx.Lock()
+	defer fr.mtx.Unlock()
+	return newestFirst(fr.panicked)
+}
+
+// Slowest returns, for every Func seen, the trace containing its slowest
+// span.
+func (fr *FlightRecorder) Slowest() map[*monkit.Func]*RecordedTrace {
+	fr.mtx.Lock()
+	defer fr.mtx.Unlock()
+	rv := make(map[*monkit.Func]*RecordedTrace, len(fr.slowest))
+	for f, slowest := range fr.slowest {
+		rv[f] = slowest.trace
+	}
+	return rv
+}
+
+// Find returns the kept trace with the given id, or nil.
+func (fr *FlightRecorder) Find(traceId int64) *RecordedTrace {
+	fr.mtx.Lock()
+	defer fr.mtx.Unlock()
+	for _, traces := range [][]*RecordedTrace{fr.recent, fr.errored, fr.panicked} {
+		for _, rt := range traces {
+			if rt.TraceId == traceId {
+				return rt
+			}
+		}
+	}
+	for _, slowest := range fr.slowest {
+		if slowest.trace.TraceId == traceId {
+			return slowest.trace
+		}
+	}
+	return nil
+}
+
+// Stats implements monkit.StatSource, reporting how many traces were
+// recorded, how many are kept or buffered, and how many spans were dropped
+// because of the buffer limits.
+func (fr *FlightRecorder) Stats(cb func(key monkit.SeriesKey, field string, val float64)) {
+	fr.mtx.Lock()
+	kept := map[*RecordedTrace]bool{}
+	for _, traces := range [][]*RecordedTrace{fr.recent, fr.errored, fr.panicked} {
+		for _, rt := range traces {
+			kept[rt] = true
+		}
+	}
+	for _, slowest := range fr.slowest {
+		kept[slowest.trace] = true
+	}
+	recorded, dropped, buffered := fr.recorded, fr.dropped, len(fr.building)
+	fr.mtx.Unlock()
+	key := monkit.NewSeriesKey("flight_recorder")
+	cb(key, "recorded", float64(recorded))
+	cb(key, "kept", float64(len(kept)))
+	cb(key, "buffered", float64(buffered))
+	cb(key, "dropped_spans", float64(dropped))
+}
+
+var (
+	_ monkit.SpanObserver = (*FlightRecorder)(nil)
+	_ monkit.StatSource   = (*FlightRecorder)(nil)
+)
@@ -0,0 +1,166 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collect
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+func recorderStats(fr *FlightRecorder) map[string]float64 {
+	stats := map[string]float64{}
+	fr.Stats(func(key monkit.SeriesKey, field string, val float64) { stats[field] = val })
+	return stats
+}
+
+func TestFlightRecorder(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	fr := NewFlightRecorder(FlightRecorderOptions{Recent: 2})
+	defer ObserveAllTraces(r, fr)()
+
+	run := func(name string, err error, panics bool) {
+		defer func() { _ = recover() }()
+		ctx := context.Background()
+		defer mon.FuncNamed("root").Task(&ctx)(nil)
+		defer mon.FuncNamed(name).Task(&ctx)(&err)
+		if panics {
+			panic("boom")
+		}
+	}
+	run("failing", errors.New("failed"), false)
+	run("panicking", nil, true)
+	run("working", nil, false)
+
+	recent := fr.Recent()
+	if len(recent) != 2 || len(recent[0].Spans) != 2 ||
+		recent[0].Root.Span.Func().ShortName() != "root" ||
+		recent[0].Spans[1].Span.Func().ShortName() != "working" {
+		t.Fatalf("unexpected recent traces: %+v", recent)
+	}
+	errored := fr.Errored()
+	if len(errored) != 1 || !errored[0].Errored || errored[0].Panicked {
+		t.Fatalf("unexpected errored traces: %+v", errored)
+	}
+	panicked := fr.Panicked()
+	if len(panicked) != 1 || !panicked[0].Panicked {
+		t.Fatalf("unexpected panicked traces: %+v", panicked)
+	}
+	if fr.Find(errored[0].TraceId) != errored[0] {
+		t.Fatal("expected to find the errored trace")
+	}
+	if slowest := fr.Slowest(); len(slowest) != 4 {
+		t.Fatalf("unexpected slowest traces: %+v", slowest)
+	}
+	if stats := recorderStats(fr); stats["recorded"] != 3 || stats["kept"] != 3 ||
+		stats["buffered"] != 0 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+}
+
+func TestFlightRecorderConcurrentFinish(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	fr := NewFlightRecorder(FlightRecorderOptions{Recent: 100})
+	defer ObserveAllTraces(r, fr)()
+
+	const traces, children = 20, 8
+	for i := 0; i < traces; i++ {
+		var started, wg sync.WaitGroup
+		release := make(chan struct{})
+		func() {
+			ctx := context.Background()
+			defer mon.FuncNamed("root").Task(&ctx)(nil)
+			for j := 0; j < children; j++ {
+				started.Add(1)
+				wg.Add(1)
+				go func(ctx context.Context) {
+					defer wg.Done()
+					defer mon.FuncNamed("child").Task(&ctx)(nil)
+					started.Done()
+					<-release
+				}(ctx)
+			}
+			started.Wait()
+		}()
+		close(release)
+		wg.Wait()
+	}
+
+	// each trace must be recorded exactly once, with all of its spans.
+	recent := fr.Recent()
+	if len(recent) != traces {
+		t.Fatalf("expected %d traces recorded, got %d", traces, len(recent))
+	}
+	for _, rt := range recent {
+		if len(rt.Spans) != children+1 {
+			t.Fatalf("expected %d spans, got %d", children+1, len(rt.Spans))
+		}
+	}
+	if stats := recorderStats(fr); stats["recorded"] != traces || stats["buffered"] != 0 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+}
+
+func TestFlightRecorderMaxTraces(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	fr := NewFlightRecorder(FlightRecorderOptions{MaxTraces: 1})
+	defer ObserveAllTraces(r, fr)()
+
+	ctx1 := context.Background()
+	done1 := mon.FuncNamed("first").Task(&ctx1)
+	ctx2 := context.Background()
+	mon.FuncNamed("second").Task(&ctx2)(nil)
+	done1(nil)
+
+	if recent := fr.Recent(); len(recent) != 1 ||
+		recent[0].Root.Span.Func().ShortName() != "first" {
+		t.Fatalf("expected only the first trace recorded, got %+v", recent)
+	}
+	if stats := recorderStats(fr); stats["dropped_spans"] != 1 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+}
+
+func TestStartFlightRecorder(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	fr, stop := StartFlightRecorder(r, FlightRecorderOptions{})
+	defer stop()
+	if FlightRecorderFor(r) != fr {
+		t.Fatal("expected the started flight recorder")
+	}
+
+	// a span starting after the rest of its trace finished is not seen, as
+	// the recorder stopped observing the trace once it was recorded.
+	ctx := context.Background()
+	func() {
+		defer mon.FuncNamed("root").Task(&ctx)(nil)
+	}()
+	mon.FuncNamed("late").Task(&ctx)(nil)
+
+	if recent := fr.Recent(); len(recent) != 1 || len(recent[0].Spans) != 1 {
+		t.Fatalf("unexpected recent traces: %+v", recent)
+	}
+	stop()
+	if FlightRecorderFor(r) != nil {
+		t.Fatal("expected no flight recorder after stop")
+	}
+}
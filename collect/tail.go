@@ -0,0 +1,209 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collect
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+// TailSamplerOptions configures a TailSampler. A trace is kept if any of the
+// enabled conditions match.
+type TailSamplerOptions struct {
+	// Errors keeps traces where any span returned an error.
+	Errors bool
+
+	// Panics keeps traces where any span panicked.
+	Panics bool
+
+	// Latency, if positive, keeps traces that took at least this long from
+	// the first span start to the last span finish.
+	Latency time.Duration
+
+	// Keep, if not nil, is consulted for traces not kept by the other
+	// conditions.
+	Keep func(spans []*FinishedSpan) bool
+
+	// MaxTraces bounds the number of traces buffered at once. Spans from new
+	// traces beyond this are dropped. Defaults to 1000.
+	MaxTraces int
+
+	// MaxSpansPerTrace bounds the number of spans buffered per trace. Later
+	// spans are dropped, but still count towards the decision. Defaults to
+	// 1000.
+	MaxSpansPerTrace int
+}
+
+// TailSampler implements the SpanObserver interface. It buffers the spans of
+// every trace it observes and, once the trace has no more running spans,
+// decides whether to keep it. Kept traces are replayed to the downstream
+// observers: Start is called for every span in start order, then Finish in
+// finish order. Discarded traces are never seen downstream.
+//
+// TailSampler is usually registered with ObserveAllTraces, after any head
+// sampling done by a monkit.Sampler on the Registry.
+type TailSampler struct {
+	opts      TailSamplerOptions
+	observers []monkit.SpanObserver
+
+	mtx       sync.Mutex
+	traces    map[*monkit.Trace]*tailTrace
+	kept      int64
+	discarded int64
+	dropped   int64
+}
+
+// tailTrace is a trace being buffered by a TailSampler.
+type tailTrace struct {
+	running  int
+	spans    []*FinishedSpan
+	errored  bool
+	panicked bool
+	first    time.Time
+	last     time.Time
+}
+
+// NewTailSampler creates a TailSampler that sends kept traces to observers.
+func NewTailSampler(opts TailSamplerOptions, observers ...monkit.SpanObserver) *TailSampler {
+	if opts.MaxTraces <= 0 {
+		opts.MaxTraces = 1000
+	}
+	if opts.MaxSpansPerTrace <= 0 {
+		opts.MaxSpansPerTrace = 1000
+	}
+	return &TailSampler{
+		opts:      opts,
+		observers: observers,
+		traces:    map[*monkit.Trace]*tailTrace{},
+	}
+}
+
+// Start is to implement the monkit.SpanObserver interface. Traces are only
+// buffered if their first span is seen, so traces joined partway through,
+// or refused because MaxTraces were already buffered, are dropped whole.
+func (ts *TailSampler) Start(s *monkit.Span) {
+	t := s.Trace()
+	ts.mtx.Lock()
+	defer ts.mtx.Unlock()
+	tt := ts.traces[t]
+	if tt == nil {
+		if t.Spans() != 1 || len(ts.traces) >= ts.opts.MaxTraces {
+			return
+		}
+		tt = &tailTrace{}
+		ts.traces[t] = tt
+	}
+	tt.running++
+}
+
+// Finish is to implement the monkit.SpanObserver interface. When the last
+// running span of a trace finishes, the trace is either replayed downstream
+// or discarded.
+func (ts *TailSampler) Finish(s *monkit.Span, err error, panicked bool,
+	finish time.Time) {
+	t := s.Trace()
+
+	ts.mtx.Lock()
+	tt := ts.traces[t]
+	if tt == nil {
+		ts.dropped++
+		ts.mtx.Unlock()
+		return
+	}
+	if len(tt.spans) < ts.opts.MaxSpansPerTrace {
+		tt.spans = append(tt.spans,
+			&FinishedSpan{Span: s, Err: err, Panicked: panicked, Finish: finish})
+	} else {
+		ts.dropped++
+	}
+	tt.errored = tt.errored || err != nil
+	tt.panicked = tt.panicked || panicked
+	if start := s.Start(); tt.first.IsZero() || start.Before(tt.first) {
+		tt.first = start
+	}
+	if finish.After(tt.last) {
+		tt.last = finish
+	}
+	tt.running--
+	done := tt.running == 0
+	if done {
+		delete(ts.traces, t)
+	}
+	ts.mtx.Unlock()
+
+	if !done {
+		return
+	}
+	keep := ts.keep(tt)
+	ts.mtx.Lock()
+	if keep {
+		ts.kept++
+	} else {
+		ts.discarded++
+	}
+	ts.mtx.Unlock()
+	if keep {
+		ts.replay(tt.spans)
+	}
+}
+
+func (ts *TailSampler) keep(tt *tailTrace) bool {
+	switch {
+	case ts.opts.Errors && tt.errored,
+		ts.opts.Panics && tt.panicked,
+		ts.opts.Latency > 0 && tt.last.Sub(tt.first) >= ts.opts.Latency:
+		return true
+	}
+	return ts.opts.Keep != nil && ts.opts.Keep(tt.spans)
+}
+
+func (ts *TailSampler) replay(spans []*FinishedSpan) {
+	StartTimeSorter(spans).Sort()
+	for _, observer := range ts.observers {
+		for _, fs := range spans {
+			observer.Start(fs.Span)
+		}
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].Finish.Before(spans[j].Finish)
+	})
+	for _, observer := range ts.observers {
+		for _, fs := range spans {
+			observer.Finish(fs.Span, fs.Err, fs.Panicked, fs.Finish)
+		}
+	}
+}
+
+// Stats implements monkit.StatSource, reporting how many traces were kept or
+// discarded, how many are buffered, and how many spans were dropped because
+// of the buffer limits.
+func (ts *TailSampler) Stats(cb func(key monkit.SeriesKey, field string, val float64)) {
+	ts.mtx.Lock()
+	kept, discarded, dropped, buffered := ts.kept, ts.discarded, ts.dropped, len(ts.traces)
+	ts.mtx.Unlock()
+	key := monkit.NewSeriesKey("tail_sampler")
+	cb(key, "kept", float64(kept))
+	cb(key, "discarded", float64(discarded))
+	cb(key, "buffered", float64(buffered))
+	cb(key, "dropped_spans", float64(dropped))
+}
+
+var (
+	_ monkit.SpanObserver = (*TailSampler)(nil)
+	_ monkit.StatSource   = (*TailSampler)(nil)
+)
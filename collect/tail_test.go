@@ -0,0 +1,137 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collect
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+// finishRecorder is a SpanObserver that records the spans it sees finish.
+type finishRecorder struct {
+	mtx      sync.Mutex
+	started  int
+	finished []*monkit.Span
+}
+
+func (r *finishRecorder) Start(s *monkit.Span) {
+	r.mtx.Lock()
+	r.started++
+	r.mtx.Unlock()
+}
+
+func (r *finishRecorder) Finish(s *monkit.Span, err error, panicked bool, finish time.Time) {
+	r.mtx.Lock()
+	r.finished = append(r.finished, s)
+	r.mtx.Unlock()
+}
+
+func TestTailSampler(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	downstream := &finishRecorder{}
+	ts := NewTailSampler(TailSamplerOptions{Errors: true}, downstream)
+	defer ObserveAllTraces(r, ts)()
+
+	run := func(fail bool) {
+		ctx := context.Background()
+		var err error
+		if fail {
+			err = errors.New("failed")
+		}
+		defer mon.FuncNamed("root").Task(&ctx)(nil)
+		defer mon.FuncNamed("child").Task(&ctx)(&err)
+	}
+	run(false)
+	run(true)
+
+	if downstream.started != 2 || len(downstream.finished) != 2 ||
+		downstream.finished[0].Func().ShortName() != "child" {
+		t.Fatalf("expected only the errored trace replayed, got %d started, %v finished",
+			downstream.started, downstream.finished)
+	}
+	stats := map[string]float64{}
+	ts.Stats(func(key monkit.SeriesKey, field string, val float64) { stats[field] = val })
+	if stats["kept"] != 1 || stats["discarded"] != 1 || stats["buffered"] != 0 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+}
+
+func TestTailSamplerConcurrentFinish(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	downstream := &finishRecorder{}
+	ts := NewTailSampler(TailSamplerOptions{Keep: func([]*FinishedSpan) bool { return true }},
+		downstream)
+	defer ObserveAllTraces(r, ts)()
+
+	const traces, children = 20, 8
+	for i := 0; i < traces; i++ {
+		var started, wg sync.WaitGroup
+		release := make(chan struct{})
+		func() {
+			ctx := context.Background()
+			defer mon.FuncNamed("root").Task(&ctx)(nil)
+			for j := 0; j < children; j++ {
+				started.Add(1)
+				wg.Add(1)
+				go func(ctx context.Context) {
+					defer wg.Done()
+					defer mon.FuncNamed("child").Task(&ctx)(nil)
+					started.Done()
+					<-release
+				}(ctx)
+			}
+			started.Wait()
+		}()
+		close(release)
+		wg.Wait()
+	}
+
+	// each trace must be decided exactly once, with all of its spans.
+	if len(downstream.finished) != traces*(children+1) {
+		t.Fatalf("expected %d spans replayed, got %d",
+			traces*(children+1), len(downstream.finished))
+	}
+	stats := map[string]float64{}
+	ts.Stats(func(key monkit.SeriesKey, field string, val float64) { stats[field] = val })
+	if stats["kept"] != traces || stats["buffered"] != 0 {
+		t.Fatalf("unexpected stats %v", stats)
+	}
+}
+
+func TestTailSamplerMaxTraces(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	downstream := &finishRecorder{}
+	ts := NewTailSampler(TailSamplerOptions{Errors: true, MaxTraces: 1}, downstream)
+	defer ObserveAllTraces(r, ts)()
+
+	ctx1 := context.Background()
+	done1 := mon.FuncNamed("first").Task(&ctx1)
+	ctx2 := context.Background()
+	err := errors.New("failed")
+	mon.FuncNamed("second").Task(&ctx2)(&err)
+	done1(&err)
+
+	if len(downstream.finished) != 1 || downstream.finished[0].Func().ShortName() != "first" {
+		t.Fatalf("expected only the first trace kept, got %v", downstream.finished)
+	}
+}
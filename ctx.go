@@ -17,6 +17,7 @@ package monkit
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spacemonkeygo/monkit/v3/monotime"
@@ -29,13 +30,15 @@ type Span struct {
 	mtx spinLock
 
 	// immutable things from construction
-	id       int64
-	start    time.Time
-	f        *Func
-	trace    *Trace
-	parent   *Span
-	parentId *int64
-	args     []interface{}
+	id        int64
+	start     time.Time
+	f         *Func
+	trace     *Trace
+	parent    *Span
+	parentId  *int64
+	args      []interface{}
+	goroutine int64
+	callers   []uintptr
 	context.Context
 
 	// protected by mtx
@@ -59,6 +62,7 @@ func SpanFromCtx(ctx context.Context) *Span {
 func newSpan(ctx context.Context, f *Func, args []interface{}, trace *Trace,
 	parentId *int64) (sctx context.Context, exit func(*error)) {
 
+	parentCtx := ctx
 	var s, parent *Span
 	if s, ok := ctx.(*Span); ok && s != nil {
 		ctx = s.Context
@@ -73,7 +77,7 @@ func newSpan(ctx context.Context, f *Func, args []interface{}, trace *Trace,
 		}
 	} else if trace == nil {
 		trace = NewTrace(NewId())
-		f.scope.r.observeTrace(trace)
+		f.scope.r.observeTrace(trace, f)
 	}
 
 	// if we're passed in an explicit parent id, then it's a remote trace,
@@ -94,6 +98,13 @@ func newSpan(ctx context.Context, f *Func, args []interface{}, trace *Trace,
 		args:     args,
 		Context:  ctx,
 	}
+	if atomic.LoadInt32(&f.scope.r.trackGoroutines) != 0 {
+		s.goroutine = currentGoroutine()
+	}
+	var leak *leakTracker
+	if atomic.LoadInt32(&f.scope.r.trackLeaks) != 0 {
+		leak = trackLeak(s)
+	}
 
 	trace.incrementSpans()
 
@@ -106,9 +117,16 @@ func newSpan(ctx context.Context, f *Func, args []interface{}, trace *Trace,
 	}
 
 	sctx = s
+	labels := f.scope.r.PprofLabels()
+	if labels != NoPprofLabels {
+		sctx = withPprofLabels(sctx, s, labels)
+	}
 	if observer != nil {
 		sctx = observer.Start(sctx, s)
 	}
+	if leak != nil {
+		leak.ctx = sctx
+	}
 
 	return sctx, func(errptr *error) {
 		rec := recover()
@@ -120,30 +138,11 @@ func newSpan(ctx context.Context, f *Func, args []interface{}, trace *Trace,
 		if errptr != nil {
 			err = *errptr
 		}
-		s.f.end(err, panicked, finish.Sub(s.start))
-
-		var children []*Span
-		s.mtx.Lock()
-		s.done = true
-		orphaned := s.orphaned
-		s.children.Iterate(func(child *Span) {
-			children = append(children, child)
-		})
-		s.mtx.Unlock()
-		for _, child := range children {
-			child.orphan()
-		}
-
-		if s.parent != nil {
-			s.parent.removeChild(s)
-			if orphaned {
-				s.f.scope.r.orphanEnd(s)
-			}
-		} else {
-			s.f.scope.r.rootSpanEnd(s)
+		s.f.end(err, panicked, finish.Sub(s.start), s)
+		if leak != nil {
+			leak.finished()
 		}
-
-		trace.decrementSpans()
+		s.unregister()
 
 		// Re-fetch the observer, in case the value has changed since newSpan
 		// was called
@@ -151,6 +150,10 @@ func newSpan(ctx context.Context, f *Func, args []interface{}, trace *Trace,
 			observer.Finish(sctx, s, err, panicked, finish)
 		}
 
+		if labels != NoPprofLabels {
+			restorePprofLabels(parentCtx)
+		}
+
 		if panicked {
 			panic(rec)
 		}
@@ -255,7 +258,7 @@ func (f *Func) RemoteTrace(ctx *context.Context, parentId int64, trace *Trace,
 	args ...interface{}) func(*error) {
 	ctx = cleanCtx(ctx)
 	if trace != nil {
-		f.scope.r.observeTrace(trace)
+		f.scope.r.observeTrace(trace, f)
 	}
 	s, exit := newSpan(*ctx, f, args, trace, &parentId)
 	if ctx != &unparented {
@@ -272,7 +275,7 @@ func (f *Func) ResetTrace(ctx *context.Context,
 		return nil
 	}
 	trace := NewTrace(NewId())
-	f.scope.r.observeTrace(trace)
+	f.scope.r.observeTrace(trace, f)
 	s, exit := newSpan(*ctx, f, args, trace, nil)
 	if ctx != &unparented {
 		*ctx = s
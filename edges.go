@@ -0,0 +1,226 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// edgeMeasurement is the measurement of edge stats, whose cardinality limit
+// bounds the edges of the Funcs of a Scope.
+const edgeMeasurement = "function_edge"
+
+// EdgeStats are the statistics of the calls a Func received from one of its
+// parents.
+type EdgeStats struct {
+	// Parent is the calling Func, or nil for calls made without a parent span,
+	// such as the first span of a trace.
+	Parent *Func
+	// Overflow is set, with a nil Parent, for the edge counting the calls from
+	// the parents beyond the Scope's cardinality limit for "function_edge".
+	Overflow bool
+	// Calls is the number of finished calls, including failed ones.
+	Calls  int64
+	Errors int64
+	Panics int64
+	// Times is the distribution of the duration of all finished calls.
+	Times *DurationDist
+}
+
+type funcEdge struct {
+	errors int64
+	panics int64
+	times  DurationDist
+}
+
+// overflowParent keys the overflow edge of a FuncStats.
+var overflowParent = new(Func)
+
+// edgeLimits bounds the number of edges of the Funcs of a Scope. It uses
+// atomics rather than the Scope's mutex, as edges are added with the mutex of
+// their FuncStats held.
+type edgeLimits struct {
+	// sync/atomic things
+	limit      int64
+	series     int64
+	overflowed int64
+}
+
+func (l *edgeLimits) setLimit(limits CardinalityLimits) {
+	limit, ok := limits.Measurements[edgeMeasurement]
+	if !ok {
+		limit = limits.Measurement
+	}
+	atomic.StoreInt64(&l.limit, int64(limit))
+}
+
+// add reserves room for a new edge, or counts an overflowed call.
+func (l *edgeLimits) add() bool {
+	if l == nil {
+		return true
+	}
+	for {
+		series := atomic.LoadInt64(&l.series)
+		if limit := atomic.LoadInt64(&l.limit); limit > 0 && series >= limit {
+			atomic.AddInt64(&l.overflowed, 1)
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&l.series, series, series+1) {
+			return true
+		}
+	}
+}
+
+// removed releases the room of n edges.
+func (l *edgeLimits) removed(n int) {
+	if l != nil && n > 0 {
+		atomic.AddInt64(&l.series, -int64(n))
+	}
+}
+
+// UseEdges makes the FuncStats keep statistics of the calls it receives from
+// each parent, returned by Edges. Edge stats cost a DurationDist per parent
+// and are limited by the "function_edge" cardinality limit of the Scope of
+// the Func, if any. See CardinalityLimits. Calling UseEdges again has no
+// effect.
+func (f *FuncStats) UseEdges() {
+	f.parentsAndMutex.Lock()
+	if f.edges == nil {
+		f.edges = map[*Func]*funcEdge{}
+	}
+	f.parentsAndMutex.Unlock()
+}
+
+// recordEdge records a finished call from parent, if edges are used.
+// f.parentsAndMutex must be held.
+func (f *FuncStats) recordEdge(parent *Func, err error, panicked bool,
+	duration time.Duration) {
+	if f.edges == nil {
+		return
+	}
+	e := f.edges[parent]
+	if e == nil {
+		if parent != nil && atomic.LoadInt32(&parent.removed) != 0 {
+			return
+		}
+		if !f.edgeLimits.add() {
+			parent = overflowParent
+			e = f.edges[parent]
+		}
+	}
+	if e == nil {
+		e = &funcEdge{}
+		initDurationDist(&e.times, f.edgeKey(parent, "_edge_times"))
+		f.edges[parent] = e
+	}
+	switch {
+	case panicked:
+		e.panics++
+	case err != nil:
+		e.errors++
+	}
+	e.times.Insert(duration)
+}
+
+// resetEdgesLocked discards the edges, keeping them in use if they were.
+// f.parentsAndMutex must be held.
+func (f *FuncStats) resetEdgesLocked() {
+	if f.edges == nil {
+		return
+	}
+	n := len(f.edges)
+	if _, ok := f.edges[overflowParent]; ok {
+		n--
+	}
+	f.edgeLimits.removed(n)
+	f.edges = map[*Func]*funcEdge{}
+}
+
+// removeEdge discards the edge from a removed parent.
+func (f *FuncStats) removeEdge(parent *Func) {
+	f.parentsAndMutex.Lock()
+	if _, ok := f.edges[parent]; ok {
+		delete(f.edges, parent)
+		f.edgeLimits.removed(1)
+	}
+	f.parentsAndMutex.Unlock()
+}
+
+func (f *FuncStats) edgeKey(parent *Func, suffix string) SeriesKey {
+	key := f.key
+	key.Measurement += suffix
+	switch parent {
+	case nil:
+		return key.WithTag("parent", "entry")
+	case overflowParent:
+		return key.WithTag("parent", OverflowTagValue)
+	}
+	return key.WithTag("parent", parent.FullName())
+}
+
+// Edges returns the statistics of the calls from each parent that has called
+// this FuncStats since UseEdges, ordered by parent id with the entry edge
+// first and the overflow edge last. Parents removed from their Scope are
+// skipped. Edges are reported in Stats under the "function_edge" and
+// "function_edge_times" measurements, tagged with the parent's full name,
+// "entry" for calls without a parent, or OverflowTagValue for the overflow
+// edge.
+func (f *FuncStats) Edges() (rv []EdgeStats) {
+	f.parentsAndMutex.Lock()
+	rv = make([]EdgeStats, 0, len(f.edges))
+	for parent, e := range f.edges {
+		if parent != nil && atomic.LoadInt32(&parent.removed) != 0 {
+			continue
+		}
+		edge := EdgeStats{
+			Parent: parent,
+			Calls:  e.times.Count,
+			Errors: e.errors,
+			Panics: e.panics,
+			Times:  e.times.Copy(),
+		}
+		if parent == overflowParent {
+			edge.Parent, edge.Overflow = nil, true
+		}
+		rv = append(rv, edge)
+	}
+	f.parentsAndMutex.Unlock()
+	sort.Slice(rv, func(i, j int) bool {
+		if rv[i].Overflow || rv[j].Overflow {
+			return rv[j].Overflow && !rv[i].Overflow
+		}
+		if rv[i].Parent == nil || rv[j].Parent == nil {
+			return rv[i].Parent == nil && rv[j].Parent != nil
+		}
+		return rv[i].Parent.Id() < rv[j].Parent.Id()
+	})
+	return rv
+}
+
+func (f *FuncStats) edgeStats(cb func(key SeriesKey, field string, val float64)) {
+	for _, e := range f.Edges() {
+		parent := e.Parent
+		if e.Overflow {
+			parent = overflowParent
+		}
+		key := f.edgeKey(parent, "_edge")
+		cb(key, "calls", float64(e.Calls))
+		cb(key, "errors", float64(e.Errors))
+		cb(key, "panics", float64(e.Panics))
+		e.Times.Stats(cb)
+	}
+}
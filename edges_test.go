@@ -0,0 +1,137 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEdgeStats(t *testing.T) {
+	r := NewRegistry()
+	mon := r.ScopeNamed("test")
+	a, b, c := mon.FuncNamed("a"), mon.FuncNamed("b"), mon.FuncNamed("c")
+	b.UseEdges()
+	c.UseEdges()
+
+	call := func(ctx context.Context, f *Func, err error) context.Context {
+		f.Task(&ctx)(&err)
+		return ctx
+	}
+	ctx := context.Background()
+	defer a.Task(&ctx)(nil)
+	call(ctx, c, nil)
+	call(ctx, c, errors.New("boom"))
+	func() {
+		ctx := ctx
+		defer b.Task(&ctx)(nil)
+		call(ctx, c, nil)
+	}()
+
+	edges := map[*Func]EdgeStats{}
+	for _, edge := range c.Edges() {
+		edges[edge.Parent] = edge
+	}
+	if len(edges) != 2 {
+		t.Fatalf("expected 2 edges, got %+v", edges)
+	}
+	if e := edges[a]; e.Calls != 2 || e.Errors != 1 || e.Times.Count != 2 {
+		t.Fatalf("unexpected edge from a: %+v", e)
+	}
+	if e := edges[b]; e.Calls != 1 || e.Errors != 0 {
+		t.Fatalf("unexpected edge from b: %+v", e)
+	}
+	if edges := b.Edges(); len(edges) != 1 || edges[0].Parent != a {
+		t.Fatalf("unexpected edges of b: %+v", edges)
+	}
+	if edges := a.Edges(); len(edges) != 0 {
+		t.Fatalf("expected no edges without UseEdges, got %+v", edges)
+	}
+
+	seen := map[string]float64{}
+	c.Stats(func(key SeriesKey, field string, val float64) {
+		if key.Measurement == "function_edge" {
+			seen[key.Tags.Get("parent")+" "+field] = val
+		}
+	})
+	if seen["test.a calls"] != 2 || seen["test.a errors"] != 1 || seen["test.b calls"] != 1 {
+		t.Fatalf("unexpected edge stats: %v", seen)
+	}
+
+	c.Reset()
+	if edges := c.Edges(); len(edges) != 0 {
+		t.Fatalf("expected no edges after reset, got %+v", edges)
+	}
+}
+
+func TestEdgeLimits(t *testing.T) {
+	mon := NewRegistry().ScopeNamed("test")
+	mon.SetCardinalityLimits(CardinalityLimits{
+		Measurements: map[string]int{"function_edge": 2},
+	})
+	leaf := mon.FuncNamed("leaf")
+	leaf.UseEdges()
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		ctx := context.Background()
+		func() {
+			defer mon.FuncNamed(name).Task(&ctx)(nil)
+			leaf.Task(&ctx)(nil)
+		}()
+	}
+
+	edges := leaf.Edges()
+	if len(edges) != 3 || !edges[2].Overflow || edges[2].Parent != nil ||
+		edges[2].Calls != 2 {
+		t.Fatalf("unexpected edges: %+v", edges)
+	}
+	seen := map[string]float64{}
+	leaf.Stats(func(key SeriesKey, field string, val float64) {
+		if key.Measurement == "function_edge" {
+			seen[key.Tags.Get("parent")+" "+field] = val
+		}
+	})
+	if seen[OverflowTagValue+" calls"] != 2 || len(seen) != 9 {
+		t.Fatalf("unexpected edge stats: %v", seen)
+	}
+	reports := mon.CardinalityReports()
+	if len(reports) != 1 || reports[0].Measurement != "function_edge" ||
+		reports[0].Series != 2 || reports[0].Limit != 2 || reports[0].Overflowed != 2 {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+
+	// removing a parent frees room for a new edge.
+	mon.RemoveFunc("a")
+	ctx := context.Background()
+	func() {
+		defer mon.FuncNamed("e").Task(&ctx)(nil)
+		leaf.Task(&ctx)(nil)
+	}()
+	parents := map[string]bool{}
+	for _, e := range leaf.Edges() {
+		if !e.Overflow {
+			parents[e.Parent.ShortName()] = true
+		}
+	}
+	if len(parents) != 2 || !parents["b"] || !parents["e"] {
+		t.Fatalf("unexpected edges after removal: %v", parents)
+	}
+	leaf.parentsAndMutex.Lock()
+	defer leaf.parentsAndMutex.Unlock()
+	if len(leaf.edges) != 3 {
+		t.Fatalf("expected the edge from the removed parent to be pruned: %v", leaf.edges)
+	}
+}
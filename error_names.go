@@ -44,6 +44,12 @@ func AddErrorNameHandler(f func(error) (string, bool)) {
 	errorNameHandlers.value.Store(handlers)
 }
 
+// ErrorName returns the name monkit gives err in its error_name series,
+// following the logic described in the AddErrorNameHandler function.
+func ErrorName(err error) string {
+	return getErrorName(err)
+}
+
 // getErrorName implements the logic described in the AddErrorNameHandler
 // function.
 func getErrorName(err error) string {
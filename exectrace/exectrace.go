@@ -0,0 +1,99 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exectrace makes monkit spans visible in the Go execution tracer,
+// as viewed with "go tool trace". Root spans become runtime/trace tasks,
+// child spans become regions within them, and span annotations are logged
+// to the task when the span finishes. Expected usage like:
+//
+//	cancel := exectrace.Register(monkit.Default)
+//	defer cancel()
+//
+// Since the Observer only does work while the execution tracer is running,
+// it is cheap to leave registered.
+package exectrace // import "github.com/spacemonkeygo/monkit/v3/exectrace"
+
+import (
+	"context"
+	"runtime/trace"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+type ctxKey int
+
+const stateKey ctxKey = 0
+
+// state is what the Observer keeps in the context of a traced span.
+type state struct {
+	span   *monkit.Span
+	task   *trace.Task
+	region *trace.Region
+}
+
+// Observer is a monkit.SpanCtxObserver that opens a runtime/trace task for
+// every span without a traced parent in its context, and a region for every
+// other span, both named after the span's Func.FullName. Regions must end on
+// the goroutine they started on, so spans that finish on a different
+// goroutine than they started on will show up incorrectly.
+type Observer struct{}
+
+// Register registers an Observer on every future trace the Registry
+// observes, and returns a function to stop.
+func Register(r *monkit.Registry) (cancel func()) {
+	return r.ObserveTraces(func(t *monkit.Trace) {
+		t.ObserveSpansCtx(Observer{})
+	})
+}
+
+// Start implements monkit.SpanCtxObserver.
+func (Observer) Start(ctx context.Context, s *monkit.Span) context.Context {
+	if !trace.IsEnabled() {
+		return ctx
+	}
+	st := &state{span: s}
+	name := s.Func().FullName()
+	if _, ok := ctx.Value(stateKey).(*state); ok {
+		st.region = trace.StartRegion(ctx, name)
+	} else {
+		ctx, st.task = trace.NewTask(ctx, name)
+	}
+	return context.WithValue(ctx, stateKey, st)
+}
+
+// Finish implements monkit.SpanCtxObserver.
+func (Observer) Finish(ctx context.Context, s *monkit.Span, err error,
+	panicked bool, finish time.Time) {
+	st, ok := ctx.Value(stateKey).(*state)
+	if !ok || st.span != s {
+		return
+	}
+	for _, annotation := range s.Annotations() {
+		trace.Log(ctx, annotation.Name, annotation.Value)
+	}
+	if panicked {
+		trace.Log(ctx, "panic", "true")
+	} else if err != nil {
+		trace.Log(ctx, "error", err.Error())
+	}
+	if st.region != nil {
+		st.region.End()
+	}
+	if st.task != nil {
+		st.task.End()
+	}
+}
+
+var _ monkit.SpanCtxObserver = Observer{}
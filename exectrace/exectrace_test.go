@@ -0,0 +1,64 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exectrace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime/trace"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+func TestObserver(t *testing.T) {
+	r := monkit.NewRegistry()
+	defer Register(r)()
+	mon := r.ScopeNamed("test")
+	parent, child := mon.FuncNamed("parent"), mon.FuncNamed("child")
+
+	ctx := context.Background()
+	parent.Task(&ctx)(nil)
+	if _, ok := ctx.Value(stateKey).(*state); ok {
+		t.Fatal("expected no task while the execution tracer is off")
+	}
+
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skip(err)
+	}
+	ctx = context.Background()
+	done := parent.Task(&ctx)
+	rootState, _ := ctx.Value(stateKey).(*state)
+	if rootState == nil || rootState.task == nil {
+		t.Fatal("expected a task for the root span")
+	}
+	childCtx := ctx
+	childDone := child.Task(&childCtx)
+	childState, _ := childCtx.Value(stateKey).(*state)
+	if childState == nil || childState.region == nil {
+		t.Fatal("expected a region for the child span")
+	}
+	monkit.SpanFromCtx(childCtx).Annotate("key", "value")
+	err := errors.New("failed")
+	childDone(&err)
+	done(nil)
+	trace.Stop()
+
+	if buf.Len() == 0 {
+		t.Fatal("expected execution trace output")
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Exemplar links an observed value to the span that produced it, so a spike
+// in a distribution can be followed to an example trace.
+type Exemplar struct {
+	Value   float64
+	TraceId int64
+	SpanId  int64
+	Time    time.Time
+}
+
+// ExemplarSet holds the exemplars kept for a distribution: those of the
+// lowest, highest and latest values, and, if histograms are in use, the
+// latest of each bucket, aligned with HistogramData.Counts. Exemplars not
+// yet seen are nil.
+type ExemplarSet struct {
+	Low    *Exemplar
+	High   *Exemplar
+	Latest *Exemplar
+
+	Buckets []*Exemplar
+}
+
+// ExemplarSource is implemented by StatSources that keep exemplars. Each
+// exemplar is reported with the SeriesKey and field of the stat it
+// illustrates, as reported by the source's Stats method.
+type ExemplarSource interface {
+	Exemplars(cb func(key SeriesKey, field string, ex Exemplar))
+}
+
+// exemplarRecorder keeps an ExemplarSet. It is not threadsafe.
+type exemplarRecorder struct {
+	low, high, latest Exemplar
+	buckets           []Exemplar
+}
+
+func (r *exemplarRecorder) record(ex Exemplar, bucket int) {
+	if r.latest.Time.IsZero() || ex.Value < r.low.Value {
+		r.low = ex
+	}
+	if r.latest.Time.IsZero() || ex.Value > r.high.Value {
+		r.high = ex
+	}
+	r.latest = ex
+	if bucket >= 0 && bucket < len(r.buckets) {
+		r.buckets[bucket] = ex
+	}
+}
+
+func (r *exemplarRecorder) set() (rv ExemplarSet) {
+	if r == nil || r.latest.Time.IsZero() {
+		return rv
+	}
+	low, high, latest := r.low, r.high, r.latest
+	rv.Low, rv.High, rv.Latest = &low, &high, &latest
+	for i := range r.buckets {
+		var ex *Exemplar
+		if !r.buckets[i].Time.IsZero() {
+			bucket := r.buckets[i]
+			ex = &bucket
+		}
+		rv.Buckets = append(rv.Buckets, ex)
+	}
+	return rv
+}
+
+// exemplars reports the set for a distribution with the given key, and the
+// bucket exemplars against the histogram with the given key and bounds.
+func (set ExemplarSet) exemplars(distKey, histKey SeriesKey, bounds []float64,
+	cb func(key SeriesKey, field string, ex Exemplar)) {
+	if set.Latest == nil {
+		return
+	}
+	cb(distKey, "min", *set.Low)
+	cb(distKey, "max", *set.High)
+	cb(distKey, "recent", *set.Latest)
+	for i, ex := range set.Buckets {
+		if ex == nil {
+			continue
+		}
+		le := "+Inf"
+		if i < len(bounds) {
+			le = strconv.FormatFloat(bounds[i], 'g', -1, 64)
+		}
+		cb(histKey.WithTag("le", le), "bucket", *ex)
+	}
+}
+
+// UseExemplars makes the FuncStats keep exemplars of its success and failure
+// times, in seconds, for spans that finish through a Task. See ExemplarSet.
+// With UseHistograms, exemplars are kept per bucket too, whichever of the two
+// is called first. Calling UseExemplars again discards the exemplars kept so
+// far.
+func (f *FuncStats) UseExemplars() {
+	f.parentsAndMutex.Lock()
+	f.successExemplars = f.newExemplarRecorder(f.successHist)
+	f.failureExemplars = f.newExemplarRecorder(f.failureHist)
+	f.parentsAndMutex.Unlock()
+}
+
+func (f *FuncStats) newExemplarRecorder(hist *HistogramData) *exemplarRecorder {
+	r := &exemplarRecorder{}
+	if hist != nil {
+		r.buckets = make([]Exemplar, len(hist.Counts))
+	}
+	return r
+}
+
+// recordExemplar must be called with the mutex held.
+func (f *FuncStats) recordExemplar(r *exemplarRecorder, hist *HistogramData,
+	s *Span, duration time.Duration) {
+	if r == nil || s == nil {
+		return
+	}
+	val := duration.Seconds()
+	bucket := -1
+	if hist != nil && len(hist.Counts) == len(r.buckets) {
+		bucket = sort.SearchFloat64s(hist.Bounds, val)
+	}
+	r.record(Exemplar{
+		Value:   val,
+		TraceId: s.trace.id,
+		SpanId:  s.id,
+		Time:    s.start.Add(duration),
+	}, bucket)
+}
+
+// SuccessExemplars returns the exemplars of success times, if UseExemplars
+// has been called.
+func (f *FuncStats) SuccessExemplars() ExemplarSet {
+	f.parentsAndMutex.Lock()
+	defer f.parentsAndMutex.Unlock()
+	return f.successExemplars.set()
+}
+
+// FailureExemplars returns the exemplars of failure times, if UseExemplars
+// has been called.
+func (f *FuncStats) FailureExemplars() ExemplarSet {
+	f.parentsAndMutex.Lock()
+	defer f.parentsAndMutex.Unlock()
+	return f.failureExemplars.set()
+}
+
+// Exemplars implements the ExemplarSource interface. Exemplars of the
+// min, max and recent fields belong to the "function_times" series, and
+// those of buckets to the "function_histogram" series.
+func (f *FuncStats) Exemplars(cb func(key SeriesKey, field string, ex Exemplar)) {
+	f.parentsAndMutex.Lock()
+	success, failure := f.successExemplars.set(), f.failureExemplars.set()
+	var bounds []float64
+	if f.successHist != nil {
+		bounds = append(bounds, f.successHist.Bounds...)
+	}
+	f.parentsAndMutex.Unlock()
+
+	histKey := f.key
+	histKey.Measurement += "_histogram"
+	success.exemplars(f.successTimes.key, histKey.WithTag("kind", "success"), bounds, cb)
+	failure.exemplars(f.failureTimes.key, histKey.WithTag("kind", "failure"), bounds, cb)
+}
+
+// Exemplars calls cb with the exemplars of every ExemplarSource in the
+// Scope, with the same keys the Scope's Stats method reports.
+func (s *Scope) Exemplars(cb func(key SeriesKey, field string, ex Exemplar)) {
+	for _, namedSource := range s.allNamedSources() {
+		if source, ok := namedSource.source.(ExemplarSource); ok {
+			source.Exemplars(func(key SeriesKey, field string, ex Exemplar) {
+				cb(key.WithTag("scope", s.name), field, ex)
+			})
+		}
+	}
+}
+
+// Exemplars calls cb with the exemplars of every ExemplarSource in the
+// Registry. The Registry's transformers are applied as they are for Stats,
+// with the exemplar value standing in for the stat value, so exemplars keep
+// matching the keys and units Stats reports. Exemplars whose stats are
+// dropped by a transformer are dropped too. DeltaTransformers and
+// RateTransformers are skipped, since exemplars would corrupt their state.
+func (r *Registry) Exemplars(cb func(key SeriesKey, field string, ex Exemplar)) {
+	var current Exemplar
+	var transformed func(SeriesKey, string, float64) = func(
+		key SeriesKey, field string, val float64) {
+		ex := current
+		ex.Value = val
+		cb(key, field, ex)
+	}
+	for _, t := range r.transformers {
+		switch t.(type) {
+		case *DeltaTransformer, *RateTransformer:
+			continue
+		}
+		transformed = t.Transform(transformed)
+	}
+	r.Scopes(func(s *Scope) {
+		s.Exemplars(func(key SeriesKey, field string, ex Exemplar) {
+			current = ex
+			transformed(key, field, ex.Value)
+		})
+	})
+}
+
+var _ ExemplarSource = (*FuncStats)(nil)
@@ -0,0 +1,79 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExemplars(t *testing.T) {
+	r := NewRegistry()
+	mon := r.ScopeNamed("test")
+	f := mon.FuncNamed("work")
+	f.UseExemplars()
+
+	var traceId int64
+	defer r.ObserveTraces(func(tr *Trace) { traceId = tr.Id() })()
+	ctx := context.Background()
+	f.Task(&ctx)(nil)
+
+	set := f.SuccessExemplars()
+	if set.Latest == nil || set.Latest.TraceId != traceId || *set.Low != *set.Latest {
+		t.Fatalf("unexpected exemplars: %+v", set)
+	}
+	if f.FailureExemplars().Latest != nil {
+		t.Fatal("expected no failure exemplars")
+	}
+
+	fields := map[string]bool{}
+	r.WithTransformers(RenameMeasurements(map[string]string{"function_times": "times"})).
+		Exemplars(func(key SeriesKey, field string, ex Exemplar) {
+			if key.Measurement != "times" || key.Tags.Get("scope") != "test" {
+				t.Fatalf("unexpected key %v", key)
+			}
+			fields[field] = true
+		})
+	if !fields["min"] || !fields["max"] || !fields["recent"] {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+
+	// stateful transformers must not see exemplar values.
+	rt := NewRateTransformer("max")
+	r.WithTransformers(rt).Exemplars(func(key SeriesKey, field string, ex Exemplar) {
+		if field == "max_rate" {
+			t.Fatal("unexpected rate of exemplars")
+		}
+	})
+	if len(rt.last) != 0 {
+		t.Fatalf("expected no rate state from exemplars, got %v", rt.last)
+	}
+}
+
+func TestExemplarsHistogramsAfter(t *testing.T) {
+	f := NewRegistry().ScopeNamed("test").FuncNamed("work")
+	f.UseExemplars()
+
+	for _, buckets := range [][]float64{{1, 2}, {1}} {
+		f.UseHistograms(buckets)
+		ctx := context.Background()
+		f.Task(&ctx)(nil)
+
+		set := f.SuccessExemplars()
+		if len(set.Buckets) != len(buckets)+1 || set.Buckets[0] == nil {
+			t.Fatalf("expected bucket exemplars for %v, got %+v", buckets, set.Buckets)
+		}
+	}
+}
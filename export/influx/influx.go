@@ -0,0 +1,248 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package influx periodically writes monkit statistics in InfluxDB line
+// protocol to an io.Writer, a UDP socket, or an HTTP /write endpoint.
+// Expected usage like:
+//
+//	exporter := influx.New(monkit.Default,
+//	  influx.HTTPSender("http://influx:8086/write?db=mydb", nil),
+//	  influx.Options{})
+//	go exporter.Run(ctx, nil)
+package influx // import "github.com/spacemonkeygo/monkit/v3/export/influx"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	mhttp "github.com/spacemonkeygo/monkit/v3/http"
+)
+
+// Sender delivers a batch of newline-terminated line protocol lines. Send
+// must not retain batch after returning.
+type Sender interface {
+	Send(ctx context.Context, batch []byte) error
+}
+
+// SenderFunc is a function that implements Sender.
+type SenderFunc func(ctx context.Context, batch []byte) error
+
+// Send implements Sender.
+func (f SenderFunc) Send(ctx context.Context, batch []byte) error { return f(ctx, batch) }
+
+// WriterSender returns a Sender that writes batches to w. Writes are
+// serialized.
+func WriterSender(w io.Writer) Sender {
+	var mtx sync.Mutex
+	return SenderFunc(func(ctx context.Context, batch []byte) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		_, err := w.Write(batch)
+		return err
+	})
+}
+
+// UDPSender sends each batch as a single UDP datagram. Keep Options.BatchSize
+// small enough that batches fit within the path MTU.
+type UDPSender struct {
+	conn net.Conn
+}
+
+// DialUDP creates a UDPSender sending to addr.
+func DialUDP(addr string) (*UDPSender, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPSender{conn: conn}, nil
+}
+
+// Send implements Sender.
+func (u *UDPSender) Send(ctx context.Context, batch []byte) error {
+	_, err := u.conn.Write(batch)
+	return err
+}
+
+// Close closes the underlying socket.
+func (u *UDPSender) Close() error { return u.conn.Close() }
+
+// HTTPSender returns a Sender that POSTs batches to url, which should be a
+// complete InfluxDB write endpoint such as
+// "http://localhost:8086/write?db=mydb". Timestamps are in nanoseconds, which
+// is the endpoint default. If client is nil, http.DefaultClient is used.
+func HTTPSender(url string, client mhttp.Client) Sender {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return SenderFunc(func(ctx context.Context, batch []byte) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(batch))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode/100 != 2 {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return fmt.Errorf("influx: %s: %s", resp.Status, bytes.TrimSpace(msg))
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	})
+}
+
+// Options configures an Exporter.
+type Options struct {
+	// Interval is how often Run collects and sends statistics. Defaults to
+	// 10 seconds.
+	Interval time.Duration
+
+	// BatchSize is the maximum number of lines handed to the Sender at once.
+	// Defaults to 5000.
+	BatchSize int
+}
+
+// Exporter periodically writes the statistics of a StatSource as line
+// protocol to a Sender.
+type Exporter struct {
+	source monkit.StatSource
+	sender Sender
+	opts   Options
+}
+
+// New creates an Exporter that sends statistics from source, usually a
+// *monkit.Registry, to sender.
+func New(source monkit.StatSource, sender Sender, opts Options) *Exporter {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 5000
+	}
+	return &Exporter{source: source, sender: sender, opts: opts}
+}
+
+// Run sends statistics every interval until ctx is canceled. Errors from
+// individual pushes are passed to errs if it is non-nil.
+func (e *Exporter) Run(ctx context.Context, errs func(error)) {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Push(ctx); err != nil && errs != nil {
+				errs(err)
+			}
+		}
+	}
+}
+
+// Push collects the current statistics and sends them in batches, stopping
+// at the first error.
+func (e *Exporter) Push(ctx context.Context) (err error) {
+	now := time.Now()
+	var batch []byte
+	lines := 0
+	e.source.Stats(func(key monkit.SeriesKey, field string, val float64) {
+		if err != nil {
+			return
+		}
+		var ok bool
+		batch, ok = AppendLine(batch, key, field, val, now)
+		if !ok {
+			return
+		}
+		lines++
+		if lines >= e.opts.BatchSize {
+			err = e.sender.Send(ctx, batch)
+			batch, lines = batch[:0], 0
+		}
+	})
+	if err != nil || lines == 0 {
+		return err
+	}
+	return e.sender.Send(ctx, batch)
+}
+
+// AppendLine appends a single line protocol line for the given series, field
+// and value, stamped with ts, to buf. Tags with an empty key or value are
+// left out, as line protocol can't represent them, and special characters in
+// names are escaped. Line protocol can't represent NaN or infinite values, so
+// those are skipped and ok is false.
+func AppendLine(buf []byte, key monkit.SeriesKey, field string, val float64,
+	ts time.Time) (out []byte, ok bool) {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return buf, false
+	}
+	buf = appendEscaped(buf, key.Measurement, ", ")
+	all := key.Tags.All()
+	keys := make([]string, 0, len(all))
+	for k, v := range all {
+		if k != "" && v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf = append(buf, ',')
+		buf = appendEscaped(buf, k, ",= ")
+		buf = append(buf, '=')
+		buf = appendEscaped(buf, all[k], ",= ")
+	}
+	buf = append(buf, ' ')
+	buf = appendEscaped(buf, field, ",= ")
+	buf = append(buf, '=')
+	buf = strconv.AppendFloat(buf, val, 'g', -1, 64)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, ts.UnixNano(), 10)
+	buf = append(buf, '\n')
+	return buf, true
+}
+
+// appendEscaped appends s to buf with a backslash before backslashes and any
+// of the given special characters. Newlines, carriage returns and tabs, which
+// would otherwise end or split the line, are written as \n, \r and \t.
+func appendEscaped(buf []byte, s, special string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\n':
+			buf = append(buf, `\n`...)
+		case c == '\r':
+			buf = append(buf, `\r`...)
+		case c == '\t':
+			buf = append(buf, `\t`...)
+		case c == '\\' || strings.IndexByte(special, c) >= 0:
+			buf = append(buf, '\\', c)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
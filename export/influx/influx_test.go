@@ -0,0 +1,75 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package influx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+func TestAppendLine(t *testing.T) {
+	key := monkit.NewSeriesKey("hit 3").WithTag("scope", "a,b")
+	line, ok := AppendLine(nil, key, "total", 4, time.Unix(0, 1234))
+	if !ok {
+		t.Fatal("expected line")
+	}
+	if exp := "hit\\ 3,scope=a\\,b total=4 1234\n"; string(line) != exp {
+		t.Fatalf("got %q, expected %q", line, exp)
+	}
+
+	key = monkit.NewSeriesKey("m").WithTag("empty", "").
+		WithTag(`a\b`, "line\nbreak").WithTag("k=v", "x y")
+	line, _ = AppendLine(nil, key, "f,1", 1, time.Unix(0, 1))
+	if exp := `m,a\\b=line\nbreak,k\=v=x\ y f\,1=1 1` + "\n"; string(line) != exp {
+		t.Fatalf("got %q, expected %q", line, exp)
+	}
+}
+
+func TestHTTPSenderBatches(t *testing.T) {
+	var mtx sync.Mutex
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		mtx.Lock()
+		bodies = append(bodies, string(body))
+		mtx.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	r := monkit.NewRegistry()
+	r.ScopeNamed("test").Meter("events").Mark(1) // rate and total
+
+	exporter := New(r, HTTPSender(srv.URL+"/write?db=test", nil), Options{BatchSize: 1})
+	if err := exporter.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 batches, got %d: %q", len(bodies), bodies)
+	}
+	for _, body := range bodies {
+		if !strings.HasPrefix(body, "events,scope=test ") || strings.Count(body, "\n") != 1 {
+			t.Fatalf("unexpected batch %q", body)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp exports finished monkit spans to an OpenTelemetry collector
+// using OTLP/HTTP with protobuf encoding. Expected usage like:
+//
+//	exporter := otlp.New(otlp.Options{
+//	  URL:         "http://localhost:4318/v1/traces",
+//	  ServiceName: "myservice",
+//	})
+//	go exporter.Run(ctx, nil)
+//	monkit.Default.ObserveTraces(func(t *monkit.Trace) {
+//	  t.ObserveSpansCtx(exporter)
+//	})
+package otlp // import "github.com/spacemonkeygo/monkit/v3/export/otlp"
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+	mhttp "github.com/spacemonkeygo/monkit/v3/http"
+	"github.com/spacemonkeygo/monkit/v3/internal/pb"
+	"github.com/spacemonkeygo/monkit/v3/internal/spanbatch"
+)
+
+// Options configures an Exporter. Only URL is required.
+type Options struct {
+	// URL is the OTLP/HTTP traces endpoint, usually ending in /v1/traces.
+	URL string
+
+	// Client is used to send requests. Defaults to http.DefaultClient.
+	Client mhttp.Client
+
+	// Header holds additional headers to send with every request.
+	Header http.Header
+
+	// ServiceName is reported as the service.name resource attribute.
+	// Defaults to the name of the running executable.
+	ServiceName string
+
+	// ResourceAttributes are additional resource attributes to report.
+	// Resource tags of traces, from monkit.Registry.WithCommonTags, are
+	// reported too, unless they conflict with these.
+	ResourceAttributes map[string]string
+
+	// QueueSize bounds the number of finished spans waiting to be sent.
+	// Spans finishing while the queue is full are dropped. Defaults to 2048.
+	QueueSize int
+
+	// BatchSize is the maximum number of spans per request. Defaults to 512.
+	BatchSize int
+
+	// FlushInterval is how often Run sends queued spans. Defaults to 5
+	// seconds.
+	FlushInterval time.Duration
+
+	// MaxRetries is how many times a failed batch is retried before its
+	// spans are counted as failed. Defaults to 3. Negative values disable
+	// retries.
+	MaxRetries int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff between
+	// retries. They default to 100 milliseconds and 10 seconds.
+	MinBackoff, MaxBackoff time.Duration
+}
+
+// Exporter is a monkit.SpanCtxObserver that queues finished spans and sends
+// them to an OTLP collector in batches.
+type Exporter struct {
+	opts     Options
+	resource []attribute
+	queue    *spanbatch.Queue
+}
+
+// New creates an Exporter. Run must be called for spans to be sent.
+func New(opts Options) *Exporter {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.ServiceName == "" {
+		opts.ServiceName = filepath.Base(os.Args[0])
+	}
+
+	resource := []attribute{{key: "service.name", value: opts.ServiceName}}
+	for key, value := range opts.ResourceAttributes {
+		if key != "service.name" {
+			resource = append(resource, attribute{key: key, value: value})
+		}
+	}
+	sort.Slice(resource[1:], func(i, j int) bool {
+		return resource[i+1].key < resource[j+1].key
+	})
+
+	e := &Exporter{opts: opts, resource: resource}
+	e.queue = spanbatch.New(spanbatch.Options{
+		QueueSize:     opts.QueueSize,
+		BatchSize:     opts.BatchSize,
+		FlushInterval: opts.FlushInterval,
+		MaxRetries:    opts.MaxRetries,
+		MinBackoff:    opts.MinBackoff,
+		MaxBackoff:    opts.MaxBackoff,
+	}, e.send)
+	return e
+}
+
+// Start implements monkit.SpanCtxObserver.
+func (e *Exporter) Start(ctx context.Context, s *monkit.Span) context.Context {
+	return ctx
+}
+
+// Finish implements monkit.SpanCtxObserver. It never blocks; if the queue is
+// full the span is dropped.
+func (e *Exporter) Finish(ctx context.Context, s *monkit.Span, err error,
+	panicked bool, finish time.Time) {
+	e.queue.Add(&collect.FinishedSpan{Span: s, Err: err, Panicked: panicked, Finish: finish})
+}
+
+// Run sends queued spans every FlushInterval, or sooner if a full batch is
+// waiting, until ctx is canceled. Errors are passed to errs if it is non-nil.
+// Call Flush after Run returns to send any remaining spans.
+func (e *Exporter) Run(ctx context.Context, errs func(error)) {
+	e.queue.Run(ctx, errs)
+}
+
+// Flush sends all currently queued spans, in batches of at most BatchSize,
+// retrying each batch with backoff on network errors, 5xx responses and 429
+// responses.
+func (e *Exporter) Flush(ctx context.Context) error {
+	return e.queue.Flush(ctx)
+}
+
+func (e *Exporter) send(ctx context.Context, batch []*collect.FinishedSpan) (retry bool, err error) {
+	return spanbatch.Post(ctx, e.opts.Client, e.opts.URL, e.opts.Header,
+		"application/x-protobuf", encodeRequest(e.resource, batch), "otlp")
+}
+
+// Stats implements monkit.StatSource, reporting how many spans have been
+// exported, dropped because the queue was full, or failed to send.
+func (e *Exporter) Stats(cb func(key monkit.SeriesKey, field string, val float64)) {
+	e.queue.Stats(monkit.NewSeriesKey("otlp_spans"), "exported", cb)
+}
+
+var (
+	_ monkit.SpanCtxObserver = (*Exporter)(nil)
+	_ monkit.StatSource      = (*Exporter)(nil)
+)
+
+// TraceID maps a monkit trace id onto a 128-bit OTLP trace id. The id is
+// stored big-endian in the low 8 bytes, which matches a 64-bit W3C trace id
+// padded with leading zeros.
+func TraceID(id int64) (rv [16]byte) {
+	binary.BigEndian.PutUint64(rv[8:], uint64(id))
+	return rv
+}
+
+// SpanID maps a monkit span id onto a 64-bit OTLP span id.
+func SpanID(id int64) (rv [8]byte) {
+	binary.BigEndian.PutUint64(rv[:], uint64(id))
+	return rv
+}
+
+type attribute struct {
+	key   string
+	value string
+}
+
+// OTLP status codes and span kinds.
+const (
+	statusError      = 2
+	spanKindInternal = 1
+)
+
+// encodeRequest returns an ExportTraceServiceRequest protobuf message holding
+// spans, grouped into one resource per distinct set of trace resource tags
+// (see monkit.Registry.WithCommonTags), and one instrumentation scope per
+// monkit Scope. Attributes in resource take precedence over resource tags.
+func encodeRequest(resource []attribute, spans []*collect.FinishedSpan) []byte {
+	byResource := map[string][]*collect.FinishedSpan{}
+	tagsByResource := map[string]*monkit.TagSet{}
+	var resources []string
+	for _, fs := range spans {
+		tags := fs.Span.Trace().ResourceTags()
+		name := tags.String()
+		if _, ok := byResource[name]; !ok {
+			resources = append(resources, name)
+			tagsByResource[name] = tags
+		}
+		byResource[name] = append(byResource[name], fs)
+	}
+	sort.Strings(resources)
+
+	var req pb.Buffer
+	for _, name := range resources {
+		// ExportTraceServiceRequest.resource_spans
+		req.Message(1, func(rs *pb.Buffer) {
+			encodeResourceSpans(rs, withResourceTags(resource, tagsByResource[name]),
+				byResource[name])
+		})
+	}
+	return req.Bytes()
+}
+
+func withResourceTags(resource []attribute, tags *monkit.TagSet) []attribute {
+	if tags.Len() == 0 {
+		return resource
+	}
+	rv := append([]attribute(nil), resource...)
+	seen := make(map[string]bool, len(resource))
+	for _, attr := range resource {
+		seen[attr.key] = true
+	}
+	var extra []attribute
+	for key, value := range tags.All() {
+		if !seen[key] {
+			extra = append(extra, attribute{key: key, value: value})
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].key < extra[j].key })
+	return append(rv, extra...)
+}
+
+func encodeResourceSpans(rs *pb.Buffer, resource []attribute,
+	spans []*collect.FinishedSpan) {
+	byScope := map[string][]*collect.FinishedSpan{}
+	var scopes []string
+	for _, fs := range spans {
+		name := fs.Span.Func().Scope().Name()
+		if _, ok := byScope[name]; !ok {
+			scopes = append(scopes, name)
+		}
+		byScope[name] = append(byScope[name], fs)
+	}
+	sort.Strings(scopes)
+
+	// ResourceSpans.resource
+	rs.Message(1, func(r *pb.Buffer) {
+		for _, attr := range resource {
+			writeStringAttribute(r, 1, attr.key, attr.value)
+		}
+	})
+	for _, scope := range scopes {
+		// ResourceSpans.scope_spans
+		rs.Message(2, func(ss *pb.Buffer) {
+			// ScopeSpans.scope
+			ss.Message(1, func(is *pb.Buffer) { is.String(1, scope) })
+			for _, fs := range byScope[scope] {
+				// ScopeSpans.spans
+				ss.Message(2, func(m *pb.Buffer) { encodeSpan(m, fs) })
+			}
+		})
+	}
+}
+
+func encodeSpan(m *pb.Buffer, fs *collect.FinishedSpan) {
+	s := fs.Span
+	traceID := TraceID(s.Trace().Id())
+	spanID := SpanID(s.Id())
+	m.BytesField(1, traceID[:])
+	m.BytesField(2, spanID[:])
+	if parentId, ok := s.ParentId(); ok {
+		parentID := SpanID(parentId)
+		m.BytesField(4, parentID[:])
+	}
+	m.String(5, s.Func().ShortName())
+	m.Uint64(6, spanKindInternal)
+	m.Fixed64(7, uint64(s.Start().UnixNano()))
+	m.Fixed64(8, uint64(fs.Finish.UnixNano()))
+
+	// Span.attributes
+	for _, annotation := range s.Annotations() {
+		writeStringAttribute(m, 9, annotation.Name, annotation.Value)
+	}
+	if args := s.Args(); len(args) > 0 {
+		m.Message(9, func(kv *pb.Buffer) {
+			kv.String(1, "monkit.args")
+			kv.Message(2, func(av *pb.Buffer) {
+				// AnyValue.array_value
+				av.Message(5, func(arr *pb.Buffer) {
+					for _, arg := range args {
+						arr.Message(1, func(v *pb.Buffer) { v.RequiredString(1, arg) })
+					}
+				})
+			})
+		})
+	}
+	if s.Orphaned() {
+		writeBoolAttribute(m, 9, "monkit.orphaned", true)
+	}
+
+	switch {
+	case fs.Panicked:
+		writeBoolAttribute(m, 9, "monkit.panicked", true)
+		m.Message(15, func(st *pb.Buffer) {
+			st.String(2, "panic")
+			st.Uint64(3, statusError)
+		})
+	case fs.Err != nil:
+		writeStringAttribute(m, 9, "error.type", monkit.ErrorName(fs.Err))
+		m.Message(15, func(st *pb.Buffer) {
+			st.String(2, fs.Err.Error())
+			st.Uint64(3, statusError)
+		})
+	}
+}
+
+func writeStringAttribute(b *pb.Buffer, field int, key, value string) {
+	b.Message(field, func(kv *pb.Buffer) {
+		kv.String(1, key)
+		kv.Message(2, func(av *pb.Buffer) { av.RequiredString(1, value) })
+	})
+}
+
+func writeBoolAttribute(b *pb.Buffer, field int, key string, value bool) {
+	b.Message(field, func(kv *pb.Buffer) {
+		kv.String(1, key)
+		kv.Message(2, func(av *pb.Buffer) { av.Bool(2, value) })
+	})
+}
@@ -0,0 +1,240 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+type pbField struct {
+	num   int
+	value uint64 // varint and fixed64 fields
+	bytes []byte // length-delimited fields
+}
+
+// decode splits a protobuf message into its fields.
+func decode(t *testing.T, b []byte) (fields []pbField) {
+	t.Helper()
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("bad tag in %x", b)
+		}
+		b = b[n:]
+		f := pbField{num: int(tag >> 3)}
+		switch tag & 7 {
+		case 0:
+			f.value, n = binary.Uvarint(b)
+			if n <= 0 {
+				t.Fatalf("bad varint in %x", b)
+			}
+			b = b[n:]
+		case 1:
+			f.value = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case 2:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				t.Fatalf("bad length in %x", b)
+			}
+			f.bytes = b[n : n+int(size)]
+			b = b[n+int(size):]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// field returns the single field with the given number.
+func field(t *testing.T, fields []pbField, num int) pbField {
+	t.Helper()
+	var found []pbField
+	for _, f := range fields {
+		if f.num == num {
+			found = append(found, f)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected one field %d, got %d", num, len(found))
+	}
+	return found[0]
+}
+
+// attributes decodes repeated KeyValue string attributes.
+func attributes(t *testing.T, fields []pbField, num int) map[string]string {
+	rv := map[string]string{}
+	for _, f := range fields {
+		if f.num == num {
+			kv := decode(t, f.bytes)
+			value := decode(t, field(t, kv, 2).bytes)
+			rv[string(field(t, kv, 1).bytes)] = string(value[0].bytes)
+		}
+	}
+	return rv
+}
+
+func TestIDs(t *testing.T) {
+	traceID := TraceID(0x0102030405060708)
+	if exp := [16]byte{8: 1, 2, 3, 4, 5, 6, 7, 8}; traceID != exp {
+		t.Fatalf("got trace id %x, expected %x", traceID, exp)
+	}
+	if spanID := SpanID(-2); spanID != [8]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe} {
+		t.Fatalf("unexpected span id %x", spanID)
+	}
+}
+
+type server struct {
+	mtx      sync.Mutex
+	bodies   [][]byte
+	failures int32
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("Content-Type") != "application/x-protobuf" {
+		http.Error(w, "bad content type", http.StatusBadRequest)
+		return
+	}
+	if atomic.AddInt32(&s.failures, -1) >= 0 {
+		http.Error(w, "try again", http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	s.mtx.Lock()
+	s.bodies = append(s.bodies, body)
+	s.mtx.Unlock()
+}
+
+func TestFlush(t *testing.T) {
+	handler := &server{failures: 2}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	r := monkit.NewRegistry()
+	exporter := New(Options{
+		URL:         srv.URL,
+		ServiceName: "svc",
+		MinBackoff:  time.Millisecond,
+	})
+	r.ObserveTraces(func(tr *monkit.Trace) { tr.ObserveSpansCtx(exporter) })
+
+	var parent, child *monkit.Span
+	func() {
+		ctx := context.Background()
+		defer r.ScopeNamed("test").FuncNamed("parent").Task(&ctx)(nil)
+		parent = monkit.SpanFromCtx(ctx)
+		err := errors.New("boom")
+		defer r.ScopeNamed("test").FuncNamed("child").Task(&ctx)(&err)
+		child = monkit.SpanFromCtx(ctx)
+	}()
+
+	if err := exporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(handler.bodies) != 1 || handler.failures != -1 {
+		t.Fatalf("expected one request after two retries, got %d (failures %d)",
+			len(handler.bodies), handler.failures)
+	}
+
+	resourceSpans := decode(t, field(t, decode(t, handler.bodies[0]), 1).bytes)
+	resource := decode(t, field(t, resourceSpans, 1).bytes)
+	if attrs := attributes(t, resource, 1); attrs["service.name"] != "svc" {
+		t.Fatalf("unexpected resource attributes %v", attrs)
+	}
+	scopeSpans := decode(t, field(t, resourceSpans, 2).bytes)
+	if scope := decode(t, field(t, scopeSpans, 1).bytes); string(field(t, scope, 1).bytes) != "test" {
+		t.Fatalf("unexpected scope %v", scope)
+	}
+
+	spans := map[string][]pbField{}
+	for _, f := range scopeSpans {
+		if f.num == 2 {
+			span := decode(t, f.bytes)
+			spans[string(field(t, span, 5).bytes)] = span
+		}
+	}
+	span := spans["child"]
+	if span == nil || spans["parent"] == nil {
+		t.Fatalf("expected parent and child spans, got %v", spans)
+	}
+	traceID := field(t, span, 1).bytes
+	if len(traceID) != 16 || binary.BigEndian.Uint64(traceID[:8]) != 0 ||
+		int64(binary.BigEndian.Uint64(traceID[8:])) != child.Trace().Id() {
+		t.Fatalf("unexpected trace id %x", traceID)
+	}
+	if id := field(t, span, 2).bytes; int64(binary.BigEndian.Uint64(id)) != child.Id() {
+		t.Fatalf("unexpected span id %x", id)
+	}
+	if id := field(t, span, 4).bytes; int64(binary.BigEndian.Uint64(id)) != parent.Id() {
+		t.Fatalf("unexpected parent span id %x", id)
+	}
+	if kind := field(t, span, 6).value; kind != spanKindInternal {
+		t.Fatalf("unexpected kind %d", kind)
+	}
+	start, end := field(t, span, 7).value, field(t, span, 8).value
+	if int64(start) != child.Start().UnixNano() || end < start {
+		t.Fatalf("unexpected times %d %d", start, end)
+	}
+	if attrs := attributes(t, span, 9); attrs["error.type"] == "" {
+		t.Fatalf("expected an error.type attribute, got %v", attrs)
+	}
+	status := decode(t, field(t, span, 15).bytes)
+	if string(field(t, status, 2).bytes) != "boom" || field(t, status, 3).value != statusError {
+		t.Fatalf("unexpected status %v", status)
+	}
+}
+
+func TestFlushClientError(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	r := monkit.NewRegistry()
+	exporter := New(Options{URL: srv.URL, MinBackoff: time.Millisecond})
+	r.ObserveTraces(func(tr *monkit.Trace) { tr.ObserveSpansCtx(exporter) })
+	ctx := context.Background()
+	r.ScopeNamed("test").FuncNamed("f").Task(&ctx)(nil)
+
+	if err := exporter.Flush(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 1 {
+		t.Fatalf("expected client errors not to be retried, got %d attempts", attempts)
+	}
+	failed := 0.0
+	exporter.Stats(func(key monkit.SeriesKey, field string, val float64) {
+		if field == "failed" {
+			failed = val
+		}
+	})
+	if failed != 1 {
+		t.Fatalf("expected 1 failed span, got %v", failed)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remotewrite pushes monkit statistics to a Prometheus remote write
+// (or OpenMetrics compatible) endpoint. It is useful for processes that don't
+// live long enough to be scraped, such as batch jobs. Expected usage like:
+//
+//	exporter := remotewrite.New(monkit.Default, remotewrite.Options{
+//	  URL: "http://prometheus:9090/api/v1/write",
+//	})
+//	go exporter.Run(ctx, func(err error) { log.Println(err) })
+//	defer exporter.Push(context.Background())
+package remotewrite // import "github.com/spacemonkeygo/monkit/v3/export/remotewrite"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	mhttp "github.com/spacemonkeygo/monkit/v3/http"
+	"github.com/spacemonkeygo/monkit/v3/internal/pb"
+	"github.com/spacemonkeygo/monkit/v3/internal/snappy"
+	"github.com/spacemonkeygo/monkit/v3/present"
+)
+
+// Options configures an Exporter. Only URL is required.
+type Options struct {
+	// URL is the remote write endpoint to POST to.
+	URL string
+
+	// Interval is how often Run collects and pushes statistics. Defaults to
+	// 15 seconds.
+	Interval time.Duration
+
+	// Client is used to send requests. Defaults to http.DefaultClient.
+	Client mhttp.Client
+
+	// Header holds additional headers to send with every request, such as
+	// authorization.
+	Header http.Header
+
+	// MaxRetries is how many times a failed push is retried before giving
+	// up. Defaults to 3. Negative values disable retries.
+	MaxRetries int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff between
+	// retries. They default to 100 milliseconds and 10 seconds.
+	MinBackoff, MaxBackoff time.Duration
+}
+
+// Exporter periodically converts the statistics of a StatSource into remote
+// write samples and pushes them.
+type Exporter struct {
+	source monkit.StatSource
+	opts   Options
+}
+
+// New creates an Exporter that pushes statistics from source, usually a
+// *monkit.Registry, according to opts.
+func New(source monkit.StatSource, opts Options) *Exporter {
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = 100 * time.Millisecond
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = 10 * time.Second
+	}
+	return &Exporter{source: source, opts: opts}
+}
+
+// Run pushes statistics every interval until ctx is canceled. Errors from
+// individual pushes are passed to errs if it is non-nil and otherwise
+// dropped, so that a flaky endpoint doesn't stop the loop. Run does not push
+// a final time when ctx is canceled; call Push for that.
+func (e *Exporter) Run(ctx context.Context, errs func(error)) {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Push(ctx); err != nil && errs != nil {
+				errs(err)
+			}
+		}
+	}
+}
+
+// Push collects the current statistics and sends them, retrying with
+// backoff on network errors, 5xx responses and 429 responses.
+func (e *Exporter) Push(ctx context.Context) error {
+	body := snappy.Encode(Encode(e.source, time.Now()))
+
+	backoff := e.opts.MinBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := e.send(ctx, body)
+		if err == nil || !retry || attempt >= e.opts.MaxRetries {
+			return err
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		backoff *= 2
+		if backoff > e.opts.MaxBackoff {
+			backoff = e.opts.MaxBackoff
+		}
+	}
+}
+
+func (e *Exporter) send(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for key, vals := range e.opts.Header {
+		req.Header[key] = vals
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := e.opts.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("remotewrite: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	return resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests, err
+}
+
+// Encode collects the statistics from source and returns them as an
+// uncompressed remote write WriteRequest protobuf message, with every sample
+// stamped with now. Metric and label names follow present.StatsPrometheus.
+func Encode(source monkit.StatSource, now time.Time) []byte {
+	timestamp := now.UnixNano() / int64(time.Millisecond)
+
+	type label struct{ name, value string }
+	var req pb.Buffer
+	source.Stats(func(key monkit.SeriesKey, field string, val float64) {
+		all := key.Tags.All()
+		keys := make([]string, 0, len(all))
+		for k := range all {
+			keys = append(keys, k)
+		}
+		labels := make([]label, 0, len(all)+1)
+		labels = append(labels, label{"__name__", present.PrometheusMetricName(key.Measurement, field)})
+		for i, name := range present.PrometheusLabelNames(keys) {
+			if keys[i] == "le" && field == "bucket" {
+				name = "le"
+			}
+			labels = append(labels, label{name, all[keys[i]]})
+		}
+		sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+
+		// WriteRequest.timeseries
+		req.Message(1, func(ts *pb.Buffer) {
+			for _, l := range labels {
+				// TimeSeries.labels
+				ts.Message(1, func(m *pb.Buffer) {
+					m.String(1, l.name)
+					m.String(2, l.value)
+				})
+			}
+			// TimeSeries.samples
+			ts.Message(2, func(m *pb.Buffer) {
+				m.Double(1, val)
+				m.Int64(2, timestamp)
+			})
+		})
+	})
+	return req.Bytes()
+}
@@ -0,0 +1,285 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+// decodeSnappy decodes a snappy block made of literals and 1 or 2 byte
+// offset copies, which is all the encoder emits.
+func decodeSnappy(t *testing.T, src []byte) []byte {
+	t.Helper()
+	n, l := binary.Uvarint(src)
+	if l <= 0 {
+		t.Fatalf("bad snappy preamble in %x", src)
+	}
+	src = src[l:]
+	dst := make([]byte, 0, n)
+	for len(src) > 0 {
+		tag := src[0]
+		var length, offset int
+		switch tag & 3 {
+		case 0:
+			length = int(tag >> 2)
+			src = src[1:]
+			if length >= 60 {
+				extra := length - 59
+				length = 0
+				for i := 0; i < extra; i++ {
+					length |= int(src[i]) << (8 * i)
+				}
+				src = src[extra:]
+			}
+			length++
+			dst = append(dst, src[:length]...)
+			src = src[length:]
+			continue
+		case 1:
+			length = 4 + int(tag>>2)&7
+			offset = int(tag>>5)<<8 | int(src[1])
+			src = src[2:]
+		case 2:
+			length = 1 + int(tag>>2)
+			offset = int(src[1]) | int(src[2])<<8
+			src = src[3:]
+		default:
+			t.Fatalf("unexpected snappy tag %x", tag)
+		}
+		for i := 0; i < length; i++ {
+			dst = append(dst, dst[len(dst)-offset])
+		}
+	}
+	if uint64(len(dst)) != n {
+		t.Fatalf("snappy length mismatch: got %d, expected %d", len(dst), n)
+	}
+	return dst
+}
+
+type pbField struct {
+	num   int
+	value uint64 // varint and fixed64 fields
+	bytes []byte // length-delimited fields
+}
+
+// decode splits a protobuf message into its fields.
+func decode(t *testing.T, b []byte) (fields []pbField) {
+	t.Helper()
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("bad tag in %x", b)
+		}
+		b = b[n:]
+		f := pbField{num: int(tag >> 3)}
+		switch tag & 7 {
+		case 0:
+			f.value, n = binary.Uvarint(b)
+			if n <= 0 {
+				t.Fatalf("bad varint in %x", b)
+			}
+			b = b[n:]
+		case 1:
+			f.value = binary.LittleEndian.Uint64(b)
+			b = b[8:]
+		case 2:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				t.Fatalf("bad length in %x", b)
+			}
+			f.bytes = b[n : n+int(size)]
+			b = b[n+int(size):]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+type series struct {
+	labels    []string // name=value, in order
+	value     float64
+	timestamp int64
+}
+
+// decodeWriteRequest decodes the time series of a WriteRequest.
+func decodeWriteRequest(t *testing.T, b []byte) (rv []series) {
+	t.Helper()
+	for _, ts := range decode(t, b) {
+		if ts.num != 1 {
+			t.Fatalf("unexpected WriteRequest field %d", ts.num)
+		}
+		var s series
+		var samples int
+		for _, f := range decode(t, ts.bytes) {
+			switch f.num {
+			case 1:
+				var name, value string
+				for _, lf := range decode(t, f.bytes) {
+					switch lf.num {
+					case 1:
+						name = string(lf.bytes)
+					case 2:
+						value = string(lf.bytes)
+					}
+				}
+				s.labels = append(s.labels, name+"="+value)
+			case 2:
+				samples++
+				for _, sf := range decode(t, f.bytes) {
+					switch sf.num {
+					case 1:
+						s.value = math.Float64frombits(sf.value)
+					case 2:
+						s.timestamp = int64(sf.value)
+					}
+				}
+			default:
+				t.Fatalf("unexpected TimeSeries field %d", f.num)
+			}
+		}
+		if samples != 1 {
+			t.Fatalf("expected one sample, got %d", samples)
+		}
+		rv = append(rv, s)
+	}
+	return rv
+}
+
+func TestPushWriteRequest(t *testing.T) {
+	source := monkit.StatSourceFunc(func(cb func(key monkit.SeriesKey, field string, val float64)) {
+		cb(monkit.NewSeriesKey("beans").
+			WithTag("__name__", "x").WithTag("le", "y").WithTag("a-b", "1").WithTag("a_b", "2"),
+			"value", 2.5)
+		cb(monkit.NewSeriesKey("function").WithTag("name", "work").WithTag("le", "0.5"),
+			"bucket", 3)
+	})
+
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ = io.ReadAll(req.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	before := time.Now().UnixNano() / int64(time.Millisecond)
+	if err := New(source, Options{URL: srv.URL}).Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().UnixNano() / int64(time.Millisecond)
+
+	got := decodeWriteRequest(t, decodeSnappy(t, body))
+	if len(got) != 2 {
+		t.Fatalf("expected 2 series, got %+v", got)
+	}
+	timestamp := got[0].timestamp
+	if timestamp < before || timestamp > after {
+		t.Fatalf("timestamp %d not in [%d, %d]", timestamp, before, after)
+	}
+	exp := []series{{
+		labels: []string{
+			"__name__=beans_value",
+			"a_b=2",
+			"a_b_2=1",
+			"exported___name__=x",
+			"exported_le=y",
+		},
+		value:     2.5,
+		timestamp: timestamp,
+	}, {
+		labels: []string{
+			"__name__=function_bucket",
+			"le=0.5",
+			"name=work",
+		},
+		value:     3,
+		timestamp: timestamp,
+	}}
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got %+v, expected %+v", got, exp)
+	}
+
+	now := time.Unix(1700000000, 123456789)
+	got = decodeWriteRequest(t, Encode(source, now))
+	for _, s := range got {
+		if s.timestamp != 1700000000123 {
+			t.Fatalf("expected millisecond timestamp, got %d", s.timestamp)
+		}
+	}
+}
+
+func TestPushRetries(t *testing.T) {
+	r := monkit.NewRegistry()
+	r.ScopeNamed("batch").Counter("jobs").Inc(1)
+
+	if !bytes.Contains(Encode(r, time.Now()), []byte("jobs_value")) {
+		t.Fatal("expected metric name in encoded request")
+	}
+
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Content-Encoding") != "snappy" ||
+			req.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("unexpected headers: %v", req.Header)
+		}
+		if body, _ := io.ReadAll(req.Body); len(body) == 0 {
+			t.Errorf("expected a request body")
+		}
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	exporter := New(r, Options{URL: srv.URL, MinBackoff: time.Millisecond})
+	if err := exporter.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestPushClientError(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	exporter := New(monkit.NewRegistry(), Options{URL: srv.URL, MinBackoff: time.Millisecond})
+	if err := exporter.Push(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 1 {
+		t.Fatalf("expected client errors not to be retried, got %d attempts", attempts)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package statsd periodically emits monkit statistics to a StatsD or
+// DogStatsD agent. Expected usage like:
+//
+//	conn, err := net.Dial("udp", "127.0.0.1:8125")
+//	...
+//	emitter := statsd.New(monkit.Default, conn, statsd.Options{DogStatsD: true})
+//	go emitter.Run(ctx, nil)
+//
+// Fields are mapped onto StatsD types as follows: total fields are sent as
+// counter increments (using a monkit.DeltaTransformer, so the first
+// collection only primes the deltas), current, high, low and most other
+// fields are sent as gauges, and distribution quantiles of *_times
+// measurements can optionally be sent as timers.
+package statsd // import "github.com/spacemonkeygo/monkit/v3/export/statsd"
+
+import (
+	"context"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+// quantileFields are the distribution fields that describe quantiles of the
+// observed values.
+var quantileFields = map[string]bool{
+	"rmin": true, "r10": true, "r50": true, "r90": true, "r99": true,
+	"rmax": true, "ravg": true,
+}
+
+// Options configures an Emitter.
+type Options struct {
+	// Interval is how often Run collects and sends statistics. Defaults to
+	// 10 seconds.
+	Interval time.Duration
+
+	// Prefix is prepended to every metric name, e.g. "myservice.".
+	Prefix string
+
+	// DogStatsD enables DogStatsD "|#key:value" tags. Otherwise tags are
+	// appended to the metric name as ",key=value" pairs, which is understood
+	// by Telegraf's StatsD input.
+	DogStatsD bool
+
+	// Timers sends the quantile fields of *_times measurements (such as
+	// function_times) as StatsD timers in milliseconds instead of gauges.
+	Timers bool
+
+	// MaxPacketSize is the largest datagram written. Defaults to 1432 bytes,
+	// which fits in a typical Ethernet MTU.
+	MaxPacketSize int
+}
+
+// Emitter periodically sends the statistics of a StatSource to a StatsD
+// agent.
+type Emitter struct {
+	source monkit.StatSource
+	w      io.Writer
+	opts   Options
+	deltas *monkit.DeltaTransformer
+}
+
+// New creates an Emitter that sends statistics from source, usually a
+// *monkit.Registry, to w. Every write to w is expected to be a single
+// datagram, such as with a UDP net.Conn.
+func New(source monkit.StatSource, w io.Writer, opts Options) *Emitter {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	if opts.MaxPacketSize <= 0 {
+		opts.MaxPacketSize = 1432
+	}
+	return &Emitter{
+		source: source,
+		w:      w,
+		opts:   opts,
+		deltas: monkit.NewDeltaTransformer(),
+	}
+}
+
+// Run sends statistics every interval until ctx is canceled. Errors from
+// individual pushes are passed to errs if it is non-nil.
+func (e *Emitter) Run(ctx context.Context, errs func(error)) {
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Push(); err != nil && errs != nil {
+				errs(err)
+			}
+		}
+	}
+}
+
+// Push collects the current statistics and sends them, packing as many
+// lines into each datagram as MaxPacketSize allows.
+func (e *Emitter) Push() (err error) {
+	var packet []byte
+	flush := func() {
+		if err == nil && len(packet) > 0 {
+			_, err = e.w.Write(packet)
+		}
+		packet = packet[:0]
+	}
+	var line []byte
+	monkit.TransformStatSource(e.source, e.deltas).Stats(
+		func(key monkit.SeriesKey, field string, val float64) {
+			if err != nil {
+				return
+			}
+			line = e.appendLines(line[:0], key, field, val)
+			if len(line) == 0 {
+				return
+			}
+			if len(packet)+len(line) > e.opts.MaxPacketSize {
+				flush()
+			}
+			packet = append(packet, line...)
+		})
+	flush()
+	return err
+}
+
+// appendLines appends the newline-terminated StatsD lines for a single
+// field to buf.
+func (e *Emitter) appendLines(buf []byte, key monkit.SeriesKey, field string,
+	val float64) []byte {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return buf
+	}
+
+	typ := "g"
+	switch {
+	case field == "total":
+		// the DeltaTransformer will follow up with a delta field.
+		return buf
+	case field == "delta":
+		typ = "c"
+		field = "total"
+	case e.opts.Timers && quantileFields[field] &&
+		strings.HasSuffix(key.Measurement, "_times"):
+		typ = "ms"
+		val *= 1000
+	}
+
+	name := e.name(key, field)
+	tags := e.dogTags(key)
+
+	if typ == "g" && val < 0 {
+		// a leading sign means a relative change to a gauge, so negative
+		// values have to be set in two steps.
+		buf = appendLine(buf, name, 0, typ, tags)
+	}
+	return appendLine(buf, name, val, typ, tags)
+}
+
+func appendLine(buf []byte, name string, val float64, typ, tags string) []byte {
+	buf = append(buf, name...)
+	buf = append(buf, ':')
+	buf = strconv.AppendFloat(buf, val, 'f', -1, 64)
+	buf = append(buf, '|')
+	buf = append(buf, typ...)
+	buf = append(buf, tags...)
+	return append(buf, '\n')
+}
+
+func (e *Emitter) name(key monkit.SeriesKey, field string) string {
+	var b strings.Builder
+	b.WriteString(e.opts.Prefix)
+	writeSanitized(&b, key.Measurement)
+	b.WriteByte('.')
+	writeSanitized(&b, field)
+	if !e.opts.DogStatsD {
+		for _, k := range sortedKeys(key.Tags) {
+			b.WriteByte(',')
+			writeSanitized(&b, k)
+			b.WriteByte('=')
+			writeSanitized(&b, key.Tags.Get(k))
+		}
+	}
+	return b.String()
+}
+
+func (e *Emitter) dogTags(key monkit.SeriesKey) string {
+	if !e.opts.DogStatsD || key.Tags.Len() == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("|#")
+	for i, k := range sortedKeys(key.Tags) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeSanitized(&b, k)
+		b.WriteByte(':')
+		writeSanitized(&b, key.Tags.Get(k))
+	}
+	return b.String()
+}
+
+func sortedKeys(tags *monkit.TagSet) []string {
+	all := tags.All()
+	keys := make([]string, 0, len(all))
+	for k := range all {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeSanitized writes s to b, replacing characters that are meaningful to
+// the StatsD protocol with underscores.
+func writeSanitized(b *strings.Builder, s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case ':', '|', '@', '#', ',', '=', ' ', '\n':
+			b.WriteByte('_')
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
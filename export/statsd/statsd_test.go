@@ -0,0 +1,114 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+type packets []string
+
+func (p *packets) Write(b []byte) (int, error) {
+	*p = append(*p, string(b))
+	return len(b), nil
+}
+
+func push(t *testing.T, e *Emitter, p *packets) string {
+	*p = nil
+	if err := e.Push(); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Join(*p, "")
+}
+
+func TestFieldTypes(t *testing.T) {
+	total := 10.0
+	source := monkit.StatSourceFunc(func(cb func(key monkit.SeriesKey, field string, val float64)) {
+		cb(monkit.NewSeriesKey("function"), "total", total)
+		cb(monkit.NewSeriesKey("function"), "current", 3)
+		cb(monkit.NewSeriesKey("function_times"), "r50", 0.25)
+		cb(monkit.NewSeriesKey("function_times"), "max", 0.5)
+	})
+	var p packets
+	e := New(source, &p, Options{Timers: true})
+
+	out := push(t, e, &p)
+	if strings.Contains(out, "|c") {
+		t.Fatalf("expected the first push to only prime the deltas, got:\n%s", out)
+	}
+	total = 15
+	out = push(t, e, &p)
+	for _, exp := range []string{
+		"function.total:5|c\n",
+		"function.current:3|g\n",
+		"function_times.r50:250|ms\n",
+		"function_times.max:0.5|g\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	source := monkit.StatSourceFunc(func(cb func(key monkit.SeriesKey, field string, val float64)) {
+		cb(monkit.NewSeriesKey("m").WithTag("scope", "a:b").WithTag("name", "f"), "current", 1)
+	})
+	var p packets
+	if out := push(t, New(source, &p, Options{DogStatsD: true}), &p); out != "m.current:1|g|#name:f,scope:a_b\n" {
+		t.Errorf("unexpected DogStatsD output %q", out)
+	}
+	if out := push(t, New(source, &p, Options{}), &p); out != "m.current,name=f,scope=a_b:1|g\n" {
+		t.Errorf("unexpected Telegraf output %q", out)
+	}
+}
+
+func TestNegativeGauge(t *testing.T) {
+	source := monkit.StatSourceFunc(func(cb func(key monkit.SeriesKey, field string, val float64)) {
+		cb(monkit.NewSeriesKey("m"), "value", -2.5)
+	})
+	var p packets
+	if out := push(t, New(source, &p, Options{}), &p); out != "m.value:0|g\nm.value:-2.5|g\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestMaxPacketSize(t *testing.T) {
+	source := monkit.StatSourceFunc(func(cb func(key monkit.SeriesKey, field string, val float64)) {
+		for i := 0; i < 10; i++ {
+			cb(monkit.NewSeriesKey("measurement"), "current", float64(i))
+		}
+		cb(monkit.NewSeriesKey("measurement"), "value", -1)
+	})
+	var p packets
+	out := push(t, New(source, &p, Options{MaxPacketSize: 60}), &p)
+	if len(p) < 2 {
+		t.Fatalf("expected several packets, got %q", p)
+	}
+	for _, packet := range p {
+		if len(packet) > 60 || !strings.HasSuffix(packet, "\n") {
+			t.Errorf("packet %q is too large or splits a line", packet)
+		}
+	}
+	if !strings.Contains(p[len(p)-1], "measurement.value:0|g\nmeasurement.value:-1|g\n") {
+		t.Errorf("expected the negative gauge lines in one packet, got %q", p)
+	}
+	if strings.Count(out, "\n") != 12 {
+		t.Errorf("expected all lines to be sent, got %q", out)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package zipkin reports finished monkit spans to a Zipkin compatible
+// collector using the v2 JSON API. Expected usage like:
+//
+//	reporter := zipkin.New(zipkin.Options{
+//	  URL: "http://localhost:9411/api/v2/spans",
+//	})
+//	go reporter.Run(ctx, nil)
+//	cancel := collect.ObserveAllTraces(monkit.Default, reporter)
+package zipkin // import "github.com/spacemonkeygo/monkit/v3/export/zipkin"
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+	mhttp "github.com/spacemonkeygo/monkit/v3/http"
+	"github.com/spacemonkeygo/monkit/v3/internal/spanbatch"
+)
+
+// Options configures a Reporter. Only URL is required.
+type Options struct {
+	// URL is the Zipkin v2 spans endpoint, usually ending in /api/v2/spans.
+	URL string
+
+	// Client is used to send requests. Defaults to http.DefaultClient.
+	Client mhttp.Client
+
+	// QueueSize bounds the number of finished spans waiting to be sent.
+	// Spans finishing while the queue is full are dropped. Defaults to 2048.
+	QueueSize int
+
+	// BatchSize is the maximum number of spans per request. Defaults to 512.
+	BatchSize int
+
+	// FlushInterval is how often Run sends queued spans. Defaults to 5
+	// seconds.
+	FlushInterval time.Duration
+
+	// MaxRetries is how many times a failed batch is retried before its
+	// spans are counted as failed. Defaults to 3. Negative values disable
+	// retries.
+	MaxRetries int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff between
+	// retries. They default to 100 milliseconds and 10 seconds.
+	MinBackoff, MaxBackoff time.Duration
+}
+
+// Reporter is a monkit.SpanObserver that queues finished spans and posts
+// them to a Zipkin collector in batches. Finishing a span only ever does a
+// non-blocking channel send, so a slow collector can't hold up traced code.
+type Reporter struct {
+	opts  Options
+	queue *spanbatch.Queue
+}
+
+// New creates a Reporter. Run must be called for spans to be sent.
+func New(opts Options) *Reporter {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	r := &Reporter{opts: opts}
+	r.queue = spanbatch.New(spanbatch.Options{
+		QueueSize:     opts.QueueSize,
+		BatchSize:     opts.BatchSize,
+		FlushInterval: opts.FlushInterval,
+		MaxRetries:    opts.MaxRetries,
+		MinBackoff:    opts.MinBackoff,
+		MaxBackoff:    opts.MaxBackoff,
+	}, r.send)
+	return r
+}
+
+// Start implements monkit.SpanObserver.
+func (r *Reporter) Start(s *monkit.Span) {}
+
+// Finish implements monkit.SpanObserver. It never blocks; if the queue is
+// full the span is dropped.
+func (r *Reporter) Finish(s *monkit.Span, err error, panicked bool, finish time.Time) {
+	r.queue.Add(&collect.FinishedSpan{Span: s, Err: err, Panicked: panicked, Finish: finish})
+}
+
+// Run sends queued spans every FlushInterval, or sooner if a full batch is
+// waiting, until ctx is canceled. Errors are passed to errs if it is non-nil.
+// Call Flush after Run returns to send any remaining spans.
+func (r *Reporter) Run(ctx context.Context, errs func(error)) {
+	r.queue.Run(ctx, errs)
+}
+
+// Flush sends all currently queued spans, in batches of at most BatchSize,
+// retrying each batch with backoff on network errors, 5xx responses and 429
+// responses.
+func (r *Reporter) Flush(ctx context.Context) error {
+	return r.queue.Flush(ctx)
+}
+
+func (r *Reporter) send(ctx context.Context, batch []*collect.FinishedSpan) (retry bool, err error) {
+	spans := make([]Span, 0, len(batch))
+	for _, fs := range batch {
+		spans = append(spans, FromFinishedSpan(fs))
+	}
+	body, err := json.Marshal(spans)
+	if err != nil {
+		return false, err
+	}
+	return spanbatch.Post(ctx, r.opts.Client, r.opts.URL, nil,
+		"application/json", body, "zipkin")
+}
+
+// Stats implements monkit.StatSource, reporting how many spans have been
+// reported, dropped because the queue was full, or failed to send.
+func (r *Reporter) Stats(cb func(key monkit.SeriesKey, field string, val float64)) {
+	r.queue.Stats(monkit.NewSeriesKey("zipkin_spans"), "reported", cb)
+}
+
+var (
+	_ monkit.SpanObserver = (*Reporter)(nil)
+	_ monkit.StatSource   = (*Reporter)(nil)
+)
+
+// Endpoint is a Zipkin v2 endpoint.
+type Endpoint struct {
+	ServiceName string `json:"serviceName,omitempty"`
+}
+
+// Span is a Zipkin v2 span in its JSON form.
+type Span struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint *Endpoint         `json:"localEndpoint,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+func hexID(id int64) string {
+	s := strconv.FormatUint(uint64(id), 16)
+	if len(s) < 16 {
+		s = "0000000000000000"[len(s):] + s
+	}
+	return s
+}
+
+// FromFinishedSpan converts a finished monkit span into a Zipkin span. The
+// local endpoint is named after the span's Scope. Since Zipkin has no
+// resource attributes, the trace's resource tags (see
+// monkit.Registry.WithCommonTags) become tags along with annotations, and
+// failures are marked with an "error" tag holding the monkit error name.
+func FromFinishedSpan(fs *collect.FinishedSpan) Span {
+	s := fs.Span
+	zs := Span{
+		TraceID:       hexID(s.Trace().Id()),
+		ID:            hexID(s.Id()),
+		Name:          s.Func().ShortName(),
+		Timestamp:     s.Start().UnixNano() / int64(time.Microsecond),
+		Duration:      int64(fs.Finish.Sub(s.Start()) / time.Microsecond),
+		LocalEndpoint: &Endpoint{ServiceName: s.Func().Scope().Name()},
+	}
+	if parentId, ok := s.ParentId(); ok {
+		zs.ParentID = hexID(parentId)
+	}
+	if zs.Duration < 1 {
+		// zipkin treats a zero duration as unknown.
+		zs.Duration = 1
+	}
+
+	annotations := s.Annotations()
+	resource := s.Trace().ResourceTags()
+	if len(annotations) > 0 || resource.Len() > 0 || fs.Err != nil || fs.Panicked {
+		zs.Tags = make(map[string]string, len(annotations)+resource.Len()+1)
+	}
+	for key, value := range resource.All() {
+		zs.Tags[key] = value
+	}
+	for _, annotation := range annotations {
+		zs.Tags[annotation.Name] = annotation.Value
+	}
+	switch {
+	case fs.Panicked:
+		zs.Tags["error"] = "panic"
+	case fs.Err != nil:
+		zs.Tags["error"] = monkit.ErrorName(fs.Err)
+	}
+	return zs
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+)
+
+func TestFromFinishedSpan(t *testing.T) {
+	r := monkit.NewRegistry().WithCommonTags(monkit.NewSeriesTag("env", "prod"))
+	mon := r.ScopeNamed("test")
+	// resource tags are attached to traces as they are observed.
+	defer r.ObserveTraces(func(*monkit.Trace) {})()
+
+	var parent, child *monkit.Span
+	func() {
+		ctx := context.Background()
+		defer mon.FuncNamed("parent").Task(&ctx)(nil)
+		parent = monkit.SpanFromCtx(ctx)
+		err := errors.New("boom")
+		defer mon.FuncNamed("child").Task(&ctx)(&err)
+		child = monkit.SpanFromCtx(ctx)
+		child.Annotate("key", "value")
+	}()
+
+	zs := FromFinishedSpan(&collect.FinishedSpan{
+		Span:   child,
+		Err:    errors.New("boom"),
+		Finish: child.Start(),
+	})
+	if len(zs.TraceID) != 16 || len(zs.ID) != 16 || zs.ParentID != hexID(parent.Id()) {
+		t.Fatalf("unexpected ids %q %q %q", zs.TraceID, zs.ID, zs.ParentID)
+	}
+	if zs.Name != "child" || zs.LocalEndpoint.ServiceName != "test" {
+		t.Fatalf("unexpected name %q or endpoint %+v", zs.Name, zs.LocalEndpoint)
+	}
+	if zs.Timestamp != child.Start().UnixNano()/int64(time.Microsecond) || zs.Duration != 1 {
+		t.Fatalf("unexpected timestamp %d or duration %d", zs.Timestamp, zs.Duration)
+	}
+	if zs.Tags["key"] != "value" || zs.Tags["env"] != "prod" || zs.Tags["error"] == "" {
+		t.Fatalf("unexpected tags %v", zs.Tags)
+	}
+
+	zs = FromFinishedSpan(&collect.FinishedSpan{Span: parent, Panicked: true,
+		Finish: parent.Start().Add(time.Millisecond)})
+	if zs.ParentID != "" || zs.Duration != 1000 || zs.Tags["error"] != "panic" {
+		t.Fatalf("unexpected span %+v", zs)
+	}
+	if hexID(1) != "0000000000000001" || hexID(-1) != "ffffffffffffffff" {
+		t.Fatalf("unexpected hex ids %q %q", hexID(1), hexID(-1))
+	}
+}
+
+func TestDrops(t *testing.T) {
+	var posted []Span
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var spans []Span
+		if err := json.NewDecoder(req.Body).Decode(&spans); err != nil {
+			t.Error(err)
+		}
+		posted = append(posted, spans...)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	r := monkit.NewRegistry()
+	reporter := New(Options{URL: srv.URL, QueueSize: 2})
+	cancel := collect.ObserveAllTraces(r, reporter)
+	defer cancel()
+	for i := 0; i < 5; i++ {
+		ctx := context.Background()
+		r.ScopeNamed("test").FuncNamed("f").Task(&ctx)(nil)
+	}
+
+	stats := func() map[string]float64 {
+		rv := map[string]float64{}
+		reporter.Stats(func(key monkit.SeriesKey, field string, val float64) {
+			rv[field] = val
+		})
+		return rv
+	}
+	if s := stats(); s["queued"] != 2 || s["dropped"] != 3 {
+		t.Fatalf("unexpected stats %v", s)
+	}
+	if err := reporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := stats(); s["queued"] != 0 || s["reported"] != 2 || len(posted) != 2 {
+		t.Fatalf("unexpected stats %v after posting %d spans", s, len(posted))
+	}
+}
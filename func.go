@@ -16,6 +16,9 @@ package monkit
 
 import (
 	"fmt"
+	"sync/atomic"
+
+	"github.com/spacemonkeygo/monkit/v3/monotime"
 )
 
 // Func represents a FuncStats bound to a particular function id, scope, and
@@ -33,11 +36,16 @@ import (
 type Func struct {
 	// sync/atomic things
 	FuncStats
+	removed int32
+
+	// mutex things (reuses mutex from parents)
+	children map[*Func]struct{}
 
 	// constructor things
 	id    int64
 	scope *Scope
 	key   SeriesKey
+	meta  *sourceMeta
 }
 
 func newFunc(s *Scope, key SeriesKey) (f *Func) {
@@ -47,9 +55,62 @@ func newFunc(s *Scope, key SeriesKey) (f *Func) {
 		key:   key,
 	}
 	initFuncStats(&f.FuncStats, key)
+	f.edgeLimits = &s.edges
 	return f
 }
 
+// start records the start of a span of f, remembering f as a child of a new
+// parent so removing either can unlink them, and marking f as used for idle
+// expiry.
+func (f *Func) start(parent *Func) {
+	if f.FuncStats.start(parent) && parent != nil {
+		parent.parentsAndMutex.Lock()
+		if parent.children == nil {
+			parent.children = map[*Func]struct{}{}
+		}
+		parent.children[f] = struct{}{}
+		parent.parentsAndMutex.Unlock()
+		if atomic.LoadInt32(&parent.removed) != 0 {
+			// parent was removed concurrently and may have missed f.
+			parent.unlinkChild(f)
+		}
+	}
+	if f.meta != nil && atomic.LoadInt64(&f.scope.idleExpiry) > 0 {
+		atomic.StoreInt64(&f.meta.used, monotime.Now().UnixNano())
+	}
+}
+
+// unlink drops the references between f and the Funcs it called or was
+// called by, so that a removed f can be garbage collected.
+func (f *Func) unlink() {
+	f.parentsAndMutex.Lock()
+	children := f.children
+	f.children = nil
+	f.parentsAndMutex.Unlock()
+	for child := range children {
+		child.parentsAndMutex.Remove(f)
+		child.removeEdge(f)
+	}
+	f.parentsAndMutex.Lock()
+	f.resetEdgesLocked()
+	f.edges = nil
+	f.parentsAndMutex.Unlock()
+	f.parents(func(parent *Func) {
+		if parent != nil {
+			parent.unlinkChild(f)
+		}
+	})
+}
+
+// unlinkChild drops the references between f and its child.
+func (f *Func) unlinkChild(child *Func) {
+	f.parentsAndMutex.Lock()
+	delete(f.children, child)
+	f.parentsAndMutex.Unlock()
+	child.parentsAndMutex.Remove(f)
+	child.removeEdge(f)
+}
+
 // ShortName returns the name of the function within the package
 func (f *Func) ShortName() string { return f.key.Tags.Get("name") }
 
@@ -65,7 +126,11 @@ func (f *Func) Id() int64 { return f.id }
 func (f *Func) Scope() *Scope { return f.scope }
 
 // Parents will call the given cb with all of the unique Funcs that so far
-// have called this Func.
+// have called this Func. Funcs removed from their Scope are skipped.
 func (f *Func) Parents(cb func(f *Func)) {
-	f.FuncStats.parents(cb)
+	f.FuncStats.parents(func(parent *Func) {
+		if parent == nil || atomic.LoadInt32(&parent.removed) == 0 {
+			cb(parent)
+		}
+	})
 }
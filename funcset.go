@@ -38,22 +38,37 @@ var (
 	nilFunc = &Func{}
 )
 
-func (s *funcSet) Add(f *Func) {
+// Add adds f to the set, and reports whether it may not have been in the set
+// before.
+func (s *funcSet) Add(f *Func) (added bool) {
 	if f == nil {
 		f = nilFunc
 	}
 	if loadFunc(&s.first) == f {
-		return
+		return false
 	}
 	if compareAndSwapFunc(&s.first, nil, f) {
-		return
+		return true
 	}
 	s.Mutex.Lock()
 	if s.rest == nil {
 		s.rest = map[*Func]struct{}{}
 	}
+	_, exists := s.rest[f]
 	s.rest[f] = struct{}{}
 	s.Mutex.Unlock()
+	return !exists
+}
+
+// Remove removes f from the set.
+func (s *funcSet) Remove(f *Func) {
+	if f == nil {
+		f = nilFunc
+	}
+	compareAndSwapFunc(&s.first, f, nil)
+	s.Mutex.Lock()
+	delete(s.rest, f)
+	s.Mutex.Unlock()
 }
 
 // Iterate loops over all unique elements of the set.
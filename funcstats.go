@@ -39,11 +39,21 @@ type FuncStats struct {
 	parentsAndMutex funcSet
 
 	// mutex things (reuses mutex from parents)
-	errors       map[string]int64
-	panics       int64
-	successTimes DurationDist
-	failureTimes DurationDist
-	key          SeriesKey
+	errors           map[string]int64
+	panics           int64
+	successTimes     DurationDist
+	failureTimes     DurationDist
+	successHist      *HistogramData
+	failureHist      *HistogramData
+	successWin       *WindowedDist
+	failureWin       *WindowedDist
+	successExemplars *exemplarRecorder
+	failureExemplars *exemplarRecorder
+	edges            map[*Func]*funcEdge
+	key              SeriesKey
+
+	// immutable things from construction
+	edgeLimits *edgeLimits
 }
 
 func initFuncStats(f *FuncStats, key SeriesKey) {
@@ -71,11 +81,46 @@ func (f *FuncStats) Reset() {
 	f.panics = 0
 	f.successTimes.Reset()
 	f.failureTimes.Reset()
+	if f.successHist != nil {
+		f.successHist.Reset()
+		f.failureHist.Reset()
+	}
+	if f.successWin != nil {
+		f.successWin.Reset()
+		f.failureWin.Reset()
+	}
+	if f.successExemplars != nil {
+		f.successExemplars = f.newExemplarRecorder(f.successHist)
+		f.failureExemplars = f.newExemplarRecorder(f.failureHist)
+	}
+	f.resetEdgesLocked()
 	f.parentsAndMutex.Unlock()
 }
 
-func (f *FuncStats) start(parent *Func) {
-	f.parentsAndMutex.Add(parent)
+// UseHistograms makes the FuncStats additionally count success and failure
+// times, in seconds, into Histograms with the given buckets. Histogram counts
+// are exact and mergeable, unlike the sampled SuccessTimes and FailureTimes.
+// They are reported in Stats under the "function_histogram" measurement with
+// the same "kind" tags as "function_times". Calling UseHistograms again
+// replaces the histograms, discarding their counts. Exemplars kept through
+// UseExemplars are discarded too, so that they follow the new buckets.
+func (f *FuncStats) UseHistograms(buckets []float64) {
+	var st, ft HistogramData
+	initHistogramData(&st, buckets)
+	initHistogramData(&ft, buckets)
+	f.parentsAndMutex.Lock()
+	f.successHist, f.failureHist = &st, &ft
+	if f.successExemplars != nil {
+		f.successExemplars = f.newExemplarRecorder(f.successHist)
+		f.failureExemplars = f.newExemplarRecorder(f.failureHist)
+	}
+	f.parentsAndMutex.Unlock()
+}
+
+// start records the start of an execution, and reports whether parent may
+// not have been seen as a parent before.
+func (f *FuncStats) start(parent *Func) (newParent bool) {
+	newParent = f.parentsAndMutex.Add(parent)
 	current := atomic.AddInt64(&f.current, 1)
 	for {
 		highwater := atomic.LoadInt64(&f.highwater)
@@ -84,23 +129,52 @@ func (f *FuncStats) start(parent *Func) {
 			break
 		}
 	}
+	return newParent
 }
 
-func (f *FuncStats) end(err error, panicked bool, duration time.Duration) {
+// end records the end of an execution. s is the span of the execution, if
+// there is one, for exemplars and to find the parent for edge stats.
+func (f *FuncStats) end(err error, panicked bool, duration time.Duration, s *Span) {
 	atomic.AddInt64(&f.current, -1)
+	var parent *Func
+	if s != nil && s.parent != nil {
+		parent = s.parent.f
+	}
 	f.parentsAndMutex.Lock()
+	f.recordEdge(parent, err, panicked, duration)
 	if panicked {
 		f.panics += 1
 		f.failureTimes.Insert(duration)
+		if f.failureHist != nil {
+			f.failureHist.insert(duration.Seconds())
+		}
+		if f.failureWin != nil {
+			f.failureWin.Insert(duration.Seconds())
+		}
+		f.recordExemplar(f.failureExemplars, f.failureHist, s, duration)
 		f.parentsAndMutex.Unlock()
 		return
 	}
 	if err == nil {
 		f.successTimes.Insert(duration)
+		if f.successHist != nil {
+			f.successHist.insert(duration.Seconds())
+		}
+		if f.successWin != nil {
+			f.successWin.Insert(duration.Seconds())
+		}
+		f.recordExemplar(f.successExemplars, f.successHist, s, duration)
 		f.parentsAndMutex.Unlock()
 		return
 	}
 	f.failureTimes.Insert(duration)
+	if f.failureHist != nil {
+		f.failureHist.insert(duration.Seconds())
+	}
+	if f.failureWin != nil {
+		f.failureWin.Insert(duration.Seconds())
+	}
+	f.recordExemplar(f.failureExemplars, f.failureHist, s, duration)
 	f.errors[getErrorName(err)] += 1
 	f.parentsAndMutex.Unlock()
 }
@@ -158,6 +232,15 @@ func (f *FuncStats) Stats(cb func(key SeriesKey, field string, val float64)) {
 	}
 	st := f.successTimes.Copy()
 	ft := f.failureTimes.Copy()
+	var sh, fh HistogramData
+	hists := f.successHist != nil
+	if hists {
+		sh, fh = f.successHist.Copy(), f.failureHist.Copy()
+	}
+	var sw, fw *WindowedDist
+	if f.successWin != nil {
+		sw, fw = f.successWin.Copy(), f.failureWin.Copy()
+	}
 	f.parentsAndMutex.Unlock()
 
 	cb(f.key, "successes", float64(st.Count))
@@ -173,6 +256,19 @@ func (f *FuncStats) Stats(cb func(key SeriesKey, field string, val float64)) {
 
 	st.Stats(cb)
 	ft.Stats(cb)
+	if sw != nil {
+		sw.Stats(cb)
+		fw.Stats(cb)
+	}
+
+	if hists {
+		key := f.key
+		key.Measurement += "_histogram"
+		sh.stats(key.WithTag("kind", "success"), cb)
+		fh.stats(key.WithTag("kind", "failure"), cb)
+	}
+
+	f.edgeStats(cb)
 }
 
 // SuccessTimes returns a DurationDist of successes
@@ -191,6 +287,63 @@ func (f *FuncStats) FailureTimes() *DurationDist {
 	return d
 }
 
+// UseWindows makes the FuncStats additionally keep WindowedDists of success
+// and failure times, in seconds, covering the last slots slot lengths of
+// time. They are reported in Stats on the "function_times" series as the
+// wcount, wmin, wmax, wavg and w-quantile fields, so recent latency isn't
+// hidden by the all-time low and high. Calling UseWindows again starts new,
+// empty windows.
+func (f *FuncStats) UseWindows(slots int, slotLength time.Duration) {
+	f.parentsAndMutex.Lock()
+	f.successWin = NewWindowedDist(f.successTimes.key, slots, slotLength)
+	f.failureWin = NewWindowedDist(f.failureTimes.key, slots, slotLength)
+	f.parentsAndMutex.Unlock()
+}
+
+// SuccessWindow returns a Sketch of success times, in seconds, within the
+// window, or nil if UseWindows hasn't been called.
+func (f *FuncStats) SuccessWindow() *Sketch {
+	f.parentsAndMutex.Lock()
+	defer f.parentsAndMutex.Unlock()
+	if f.successWin == nil {
+		return nil
+	}
+	return f.successWin.Window()
+}
+
+// FailureWindow returns a Sketch of failure times, in seconds, within the
+// window, or nil if UseWindows hasn't been called.
+func (f *FuncStats) FailureWindow() *Sketch {
+	f.parentsAndMutex.Lock()
+	defer f.parentsAndMutex.Unlock()
+	if f.failureWin == nil {
+		return nil
+	}
+	return f.failureWin.Window()
+}
+
+// SuccessHistogram returns a copy of the success time Histogram, in seconds,
+// and false if UseHistograms hasn't been called.
+func (f *FuncStats) SuccessHistogram() (HistogramData, bool) {
+	f.parentsAndMutex.Lock()
+	defer f.parentsAndMutex.Unlock()
+	if f.successHist == nil {
+		return HistogramData{}, false
+	}
+	return f.successHist.Copy(), true
+}
+
+// FailureHistogram returns a copy of the failure time Histogram, in seconds,
+// and false if UseHistograms hasn't been called.
+func (f *FuncStats) FailureHistogram() (HistogramData, bool) {
+	f.parentsAndMutex.Lock()
+	defer f.parentsAndMutex.Unlock()
+	if f.failureHist == nil {
+		return HistogramData{}, false
+	}
+	return f.failureHist.Copy(), true
+}
+
 // Observe starts the stopwatch for observing this function and returns a
 // function to be called at the end of the function execution. Expected usage
 // like:
@@ -211,7 +364,7 @@ func (f *FuncStats) Observe() func(errptr *error) {
 		if errptr != nil {
 			err = *errptr
 		}
-		f.end(err, panicked, finish.Sub(start))
+		f.end(err, panicked, finish.Sub(start), nil)
 		if panicked {
 			panic(rec)
 		}
@@ -0,0 +1,238 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Histogram counts observed values into fixed buckets. Unlike the sampled
+// reservoirs of IntDist, FloatDist and DurationDist, bucket counts are exact
+// and histograms with the same buckets can be merged, for instance across
+// processes. Should be constructed with NewHistogram, though it may be more
+// convenient to use the Histogram accessor on a given Scope. Expected
+// creation is like:
+//
+//	var mon = monkit.Package()
+//
+//	func MyFunc() {
+//	  mon.Histogram("size", monkit.ExponentialBuckets(1, 2, 20)).Observe(val)
+//	}
+//
+// Histogram implements StatSource. Buckets are reported cumulatively, like
+// Prometheus histograms: field "bucket" with an "le" tag holding the bucket's
+// upper bound, along with "count" and "sum" fields.
+type Histogram struct {
+	mtx  sync.Mutex
+	data HistogramData
+	key  SeriesKey
+}
+
+// HistogramData is a snapshot of a Histogram. Counts[i] is the number of
+// observed values v with Bounds[i-1] < v <= Bounds[i], and the last entry of
+// Counts, one past the end of Bounds, counts values above every bound.
+type HistogramData struct {
+	Bounds []float64
+	Counts []int64
+	Sum    float64
+	Count  int64
+}
+
+// NewHistogram creates a Histogram with the given bucket upper bounds, which
+// are sorted and deduplicated.
+func NewHistogram(key SeriesKey, buckets []float64) *Histogram {
+	h := &Histogram{key: key}
+	initHistogramData(&h.data, buckets)
+	return h
+}
+
+func initHistogramData(d *HistogramData, buckets []float64) {
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+	uniq := bounds[:0]
+	for i, b := range bounds {
+		if math.IsNaN(b) || math.IsInf(b, 1) || (i > 0 && b == bounds[i-1]) {
+			continue
+		}
+		uniq = append(uniq, b)
+	}
+	d.Bounds = uniq
+	d.Counts = make([]int64, len(uniq)+1)
+}
+
+// Observe adds a value to the histogram. NaN values are ignored.
+func (h *Histogram) Observe(val float64) {
+	h.mtx.Lock()
+	h.data.insert(val)
+	h.mtx.Unlock()
+}
+
+func (d *HistogramData) insert(val float64) {
+	if math.IsNaN(val) {
+		return
+	}
+	d.Counts[sort.SearchFloat64s(d.Bounds, val)]++
+	d.Sum += val
+	d.Count++
+}
+
+// Data returns a copy of the histogram's current state.
+func (h *Histogram) Data() HistogramData {
+	h.mtx.Lock()
+	d := h.data.Copy()
+	h.mtx.Unlock()
+	return d
+}
+
+// Merge adds the counts from other into the histogram. It fails if the
+// buckets differ.
+func (h *Histogram) Merge(other HistogramData) error {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.data.Merge(other)
+}
+
+// Reset zeroes all counts.
+func (h *Histogram) Reset() {
+	h.mtx.Lock()
+	h.data.Reset()
+	h.mtx.Unlock()
+}
+
+// Stats implements the StatSource interface.
+func (h *Histogram) Stats(cb func(key SeriesKey, field string, val float64)) {
+	d := h.Data()
+	d.stats(h.key, cb)
+}
+
+// Copy returns a deep copy of d.
+func (d HistogramData) Copy() HistogramData {
+	d.Bounds = append([]float64(nil), d.Bounds...)
+	d.Counts = append([]int64(nil), d.Counts...)
+	return d
+}
+
+// Reset zeroes all counts.
+func (d *HistogramData) Reset() {
+	for i := range d.Counts {
+		d.Counts[i] = 0
+	}
+	d.Sum, d.Count = 0, 0
+}
+
+// Merge adds the counts from other into d. It fails if the buckets differ.
+func (d *HistogramData) Merge(other HistogramData) error {
+	if len(d.Bounds) != len(other.Bounds) || len(other.Counts) != len(d.Counts) {
+		return fmt.Errorf("monkit: can't merge histograms with different buckets")
+	}
+	for i, b := range d.Bounds {
+		if other.Bounds[i] != b {
+			return fmt.Errorf("monkit: can't merge histograms with different buckets")
+		}
+	}
+	for i, c := range other.Counts {
+		d.Counts[i] += c
+	}
+	d.Sum += other.Sum
+	d.Count += other.Count
+	return nil
+}
+
+// Quantile estimates the value at the given quantile, where
+// 0 <= quantile <= 1, by interpolating linearly within the bucket the
+// quantile falls in. Values in the overflow bucket are reported as the
+// highest bound.
+func (d HistogramData) Quantile(quantile float64) float64 {
+	if d.Count == 0 || len(d.Bounds) == 0 {
+		return math.NaN()
+	}
+	rank := quantile * float64(d.Count)
+	var seen float64
+	for i, c := range d.Counts {
+		if c == 0 || seen+float64(c) < rank {
+			seen += float64(c)
+			continue
+		}
+		if i == len(d.Bounds) {
+			break
+		}
+		upper := d.Bounds[i]
+		lower := math.Min(0, upper)
+		if i > 0 {
+			lower = d.Bounds[i-1]
+		}
+		return lower + (upper-lower)*(rank-seen)/float64(c)
+	}
+	return d.Bounds[len(d.Bounds)-1]
+}
+
+func (d HistogramData) stats(key SeriesKey, cb func(key SeriesKey, field string, val float64)) {
+	var cumulative int64
+	for i, b := range d.Bounds {
+		cumulative += d.Counts[i]
+		cb(key.WithTag("le", strconv.FormatFloat(b, 'g', -1, 64)), "bucket", float64(cumulative))
+	}
+	cb(key.WithTag("le", "+Inf"), "bucket", float64(d.Count))
+	cb(key, "count", float64(d.Count))
+	cb(key, "sum", d.Sum)
+}
+
+// LinearBuckets returns count bucket bounds, starting at start and each
+// width apart.
+func LinearBuckets(start, width float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start + float64(i)*width
+	}
+	return buckets
+}
+
+// ExponentialBuckets returns count bucket bounds, starting at start and each
+// factor times the previous one.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// LogLinearBuckets returns HDR-style bucket bounds covering min to max. Each
+// power of two range is split into subBuckets linear buckets, so any value
+// in range is reported with a relative error of at most 1/subBuckets.
+func LogLinearBuckets(min, max float64, subBuckets int) []float64 {
+	if min <= 0 || max < min || subBuckets < 1 {
+		return nil
+	}
+	var buckets []float64
+	for base := math.Exp2(math.Floor(math.Log2(min))); ; base *= 2 {
+		width := base / float64(subBuckets)
+		for i := 1; i <= subBuckets; i++ {
+			bound := base + float64(i)*width
+			if bound < min {
+				continue
+			}
+			buckets = append(buckets, bound)
+			if bound >= max {
+				return buckets
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram(NewSeriesKey("h"), []float64{10, 1, 5, 5})
+	for _, v := range []float64{0.5, 1, 3, 7, 100, math.NaN()} {
+		h.Observe(v)
+	}
+
+	buckets := map[string]float64{}
+	h.Stats(func(key SeriesKey, field string, val float64) {
+		if field == "bucket" {
+			buckets[key.Tags.Get("le")] = val
+		}
+	})
+	expected := map[string]float64{"1": 2, "5": 3, "10": 4, "+Inf": 5}
+	for le, count := range expected {
+		if buckets[le] != count {
+			t.Fatalf("bucket %s: expected %v, got %v", le, count, buckets[le])
+		}
+	}
+
+	d := h.Data()
+	if err := h.Merge(d); err != nil {
+		t.Fatal(err)
+	}
+	if d = h.Data(); d.Count != 10 || d.Sum != 2*111.5 {
+		t.Fatalf("unexpected merged data: %+v", d)
+	}
+	if err := h.Merge(NewHistogram(NewSeriesKey("h"), []float64{1}).Data()); err == nil {
+		t.Fatal("expected merging different buckets to fail")
+	}
+}
+
+func TestLogLinearBuckets(t *testing.T) {
+	buckets := LogLinearBuckets(0.001, 10, 8)
+	if buckets[0] < 0.001 || buckets[len(buckets)-1] < 10 {
+		t.Fatalf("buckets don't cover range: %v", buckets)
+	}
+	for i := 1; i < len(buckets); i++ {
+		if relErr := (buckets[i] - buckets[i-1]) / buckets[i]; relErr > 1.0/8 {
+			t.Fatalf("relative error %v too large at %v", relErr, buckets[i])
+		}
+	}
+}
+
+func TestFuncStatsHistograms(t *testing.T) {
+	f := NewFuncStats(NewSeriesKey("function"))
+	f.UseHistograms(ExponentialBuckets(0.001, 10, 4))
+	f.end(nil, false, 50*time.Millisecond, nil)
+	f.end(nil, true, 2*time.Second, nil)
+
+	st, ok := f.SuccessHistogram()
+	if !ok || st.Count != 1 || st.Counts[2] != 1 {
+		t.Fatalf("unexpected success histogram: %+v", st)
+	}
+	ft, _ := f.FailureHistogram()
+	if ft.Count != 1 || ft.Counts[4] != 1 || math.Abs(ft.Sum-2) > 1e-9 {
+		t.Fatalf("unexpected failure histogram: %+v", ft)
+	}
+}
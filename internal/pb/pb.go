@@ -0,0 +1,124 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pb is a tiny protocol buffer wire format encoder, just big enough
+// for the exporters in this module to avoid depending on a protobuf library.
+package pb
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// Buffer accumulates an encoded protobuf message.
+type Buffer struct {
+	buf []byte
+}
+
+// Bytes returns the encoded message.
+func (b *Buffer) Bytes() []byte { return b.buf }
+
+// Reset clears the buffer for reuse.
+func (b *Buffer) Reset() { b.buf = b.buf[:0] }
+
+func (b *Buffer) varint(v uint64) {
+	b.buf = binary.AppendUvarint(b.buf, v)
+}
+
+func (b *Buffer) tag(field int, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+// Uint64 writes a varint field. Zero values are omitted.
+func (b *Buffer) Uint64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, wireVarint)
+	b.varint(v)
+}
+
+// Int64 writes an int64 varint field. Zero values are omitted.
+func (b *Buffer) Int64(field int, v int64) {
+	b.Uint64(field, uint64(v))
+}
+
+// Bool writes a bool field. False values are omitted.
+func (b *Buffer) Bool(field int, v bool) {
+	if v {
+		b.Uint64(field, 1)
+	}
+}
+
+// Double writes a double field. Zero values are omitted.
+func (b *Buffer) Double(field int, v float64) {
+	if v == 0 && !math.Signbit(v) {
+		return
+	}
+	b.tag(field, wireFixed64)
+	b.buf = binary.LittleEndian.AppendUint64(b.buf, math.Float64bits(v))
+}
+
+// Fixed64 writes a fixed64 field. Zero values are omitted.
+func (b *Buffer) Fixed64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, wireFixed64)
+	b.buf = binary.LittleEndian.AppendUint64(b.buf, v)
+}
+
+// BytesField writes a length-delimited bytes field. Empty values are omitted.
+func (b *Buffer) BytesField(field int, v []byte) {
+	if len(v) == 0 {
+		return
+	}
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(v)))
+	b.buf = append(b.buf, v...)
+}
+
+// String writes a string field. Empty values are omitted.
+func (b *Buffer) String(field int, v string) {
+	if v == "" {
+		return
+	}
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(v)))
+	b.buf = append(b.buf, v...)
+}
+
+// RequiredString writes a string field even if it is empty, which is
+// needed for members of a oneof.
+func (b *Buffer) RequiredString(field int, v string) {
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(v)))
+	b.buf = append(b.buf, v...)
+}
+
+// Message writes an embedded message field whose contents are written by
+// fn. Embedded messages are always written, even if empty.
+func (b *Buffer) Message(field int, fn func(m *Buffer)) {
+	var m Buffer
+	fn(&m)
+	b.tag(field, wireBytes)
+	b.varint(uint64(len(m.buf)))
+	b.buf = append(b.buf, m.buf...)
+}
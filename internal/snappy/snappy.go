@@ -0,0 +1,129 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package snappy implements an encoder for the snappy block format, as used
+// by the Prometheus remote write protocol. Only encoding is supported, and
+// the encoder favors simplicity over compression ratio.
+package snappy
+
+import (
+	"encoding/binary"
+)
+
+const (
+	tagLiteral = 0x00
+	tagCopy1   = 0x01
+	tagCopy2   = 0x02
+
+	// maxBlockSize bounds the input handed to encodeBlock so that all copy
+	// offsets fit in two bytes.
+	maxBlockSize = 65536
+
+	// inputs shorter than this are not worth looking for matches in.
+	minNonLiteralBlockSize = 1 + 1 + 16
+
+	tableBits = 14
+	tableSize = 1 << tableBits
+)
+
+// Encode returns the snappy block encoding of src.
+func Encode(src []byte) []byte {
+	dst := binary.AppendUvarint(make([]byte, 0, len(src)/2+16), uint64(len(src)))
+	for len(src) > 0 {
+		p := src
+		if len(p) > maxBlockSize {
+			p = p[:maxBlockSize]
+		}
+		src = src[len(p):]
+		if len(p) < minNonLiteralBlockSize {
+			dst = emitLiteral(dst, p)
+		} else {
+			dst = encodeBlock(dst, p)
+		}
+	}
+	return dst
+}
+
+func load32(b []byte, i int) uint32 {
+	return binary.LittleEndian.Uint32(b[i:])
+}
+
+func hash(u uint32) uint32 {
+	return (u * 0x1e35a7bd) >> (32 - tableBits)
+}
+
+func encodeBlock(dst, src []byte) []byte {
+	// table holds positions plus one, so that zero means empty.
+	var table [tableSize]int32
+	nextEmit := 0
+	s := 0
+	for s+4 <= len(src) {
+		cur := load32(src, s)
+		h := hash(cur)
+		candidate := int(table[h]) - 1
+		table[h] = int32(s + 1)
+		if candidate < 0 || load32(src, candidate) != cur {
+			s++
+			continue
+		}
+
+		dst = emitLiteral(dst, src[nextEmit:s])
+
+		base := s
+		s += 4
+		candidate += 4
+		for s < len(src) && src[s] == src[candidate] {
+			s++
+			candidate++
+		}
+		dst = emitCopy(dst, base-(candidate-(s-base)), s-base)
+		nextEmit = s
+	}
+	return emitLiteral(dst, src[nextEmit:])
+}
+
+func emitLiteral(dst, lit []byte) []byte {
+	if len(lit) == 0 {
+		return dst
+	}
+	n := uint32(len(lit) - 1)
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n)<<2|tagLiteral)
+	case n < 1<<8:
+		dst = append(dst, 60<<2|tagLiteral, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2|tagLiteral, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2|tagLiteral, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2|tagLiteral, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, lit...)
+}
+
+func emitCopy(dst []byte, offset, length int) []byte {
+	for length >= 68 {
+		dst = append(dst, 63<<2|tagCopy2, byte(offset), byte(offset>>8))
+		length -= 64
+	}
+	if length > 64 {
+		dst = append(dst, 59<<2|tagCopy2, byte(offset), byte(offset>>8))
+		length -= 60
+	}
+	if length >= 12 || offset >= 2048 {
+		return append(dst, byte(length-1)<<2|tagCopy2, byte(offset), byte(offset>>8))
+	}
+	return append(dst, byte(offset>>8)<<5|byte(length-4)<<2|tagCopy1, byte(offset))
+}
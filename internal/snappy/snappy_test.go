@@ -0,0 +1,103 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snappy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// decode is a straightforward snappy block decoder used to check Encode.
+func decode(src []byte) ([]byte, error) {
+	n, l := binary.Uvarint(src)
+	if l <= 0 {
+		return nil, fmt.Errorf("bad preamble")
+	}
+	src = src[l:]
+	dst := make([]byte, 0, n)
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 3 {
+		case tagLiteral:
+			length := int(tag >> 2)
+			src = src[1:]
+			if length >= 60 {
+				extra := length - 59
+				length = 0
+				for i := 0; i < extra; i++ {
+					length |= int(src[i]) << (8 * i)
+				}
+				src = src[extra:]
+			}
+			length++
+			dst = append(dst, src[:length]...)
+			src = src[length:]
+		case tagCopy1:
+			length := 4 + int(tag>>2)&7
+			offset := int(tag>>5)<<8 | int(src[1])
+			src = src[2:]
+			for i := 0; i < length; i++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+		case tagCopy2:
+			length := 1 + int(tag>>2)
+			offset := int(src[1]) | int(src[2])<<8
+			src = src[3:]
+			for i := 0; i < length; i++ {
+				dst = append(dst, dst[len(dst)-offset])
+			}
+		default:
+			return nil, fmt.Errorf("unexpected tag %x", tag)
+		}
+	}
+	if uint64(len(dst)) != n {
+		return nil, fmt.Errorf("length mismatch: got %d, expected %d", len(dst), n)
+	}
+	return dst, nil
+}
+
+func TestRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	random := make([]byte, 200000)
+	rng.Read(random)
+
+	for _, input := range [][]byte{
+		nil,
+		[]byte("a"),
+		[]byte("hello, world"),
+		[]byte(strings.Repeat("function_times_count", 1000)),
+		bytes.Repeat([]byte{0}, 300000),
+		random,
+		append([]byte(strings.Repeat("abcdefgh", 9000)), random[:70000]...),
+	} {
+		encoded := Encode(input)
+		decoded, err := decode(encoded)
+		if err != nil {
+			t.Fatalf("len %d: %v", len(input), err)
+		}
+		if !bytes.Equal(decoded, input) {
+			t.Fatalf("len %d: round trip mismatch", len(input))
+		}
+	}
+
+	repetitive := []byte(strings.Repeat("function_times_count", 1000))
+	if n := len(Encode(repetitive)); n > len(repetitive)/10 {
+		t.Fatalf("expected repetitive input to compress, got %d bytes", n)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spanbatch is the bounded queue, batching and retry logic shared by
+// the span exporters in this module.
+package spanbatch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+	mhttp "github.com/spacemonkeygo/monkit/v3/http"
+)
+
+// Options configures a Queue. Zero values get the defaults documented on the
+// exporters' Options.
+type Options struct {
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+
+	MaxRetries             int
+	MinBackoff, MaxBackoff time.Duration
+}
+
+// SendFunc sends a batch of spans. retry reports whether a failure is worth
+// retrying.
+type SendFunc func(ctx context.Context, batch []*collect.FinishedSpan) (retry bool, err error)
+
+// Queue holds finished spans until they are sent in batches by send.
+// Adding a span only ever does a non-blocking channel send, so a slow
+// collector can't hold up traced code.
+type Queue struct {
+	// sync/atomic things
+	sent    int64
+	dropped int64
+	failed  int64
+
+	opts     Options
+	send     SendFunc
+	queue    chan *collect.FinishedSpan
+	kick     chan struct{}
+	flushMtx sync.Mutex
+}
+
+// New creates a Queue that sends its spans with send.
+func New(opts Options, send SendFunc) *Queue {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 2048
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 512
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.MinBackoff <= 0 {
+		opts.MinBackoff = 100 * time.Millisecond
+	}
+	if opts.MaxBackoff < opts.MinBackoff {
+		opts.MaxBackoff = 10 * time.Second
+	}
+	return &Queue{
+		opts:  opts,
+		send:  send,
+		queue: make(chan *collect.FinishedSpan, opts.QueueSize),
+		kick:  make(chan struct{}, 1),
+	}
+}
+
+// Add queues fs. It never blocks; if the queue is full the span is dropped.
+func (q *Queue) Add(fs *collect.FinishedSpan) {
+	select {
+	case q.queue <- fs:
+		if len(q.queue) >= q.opts.BatchSize {
+			select {
+			case q.kick <- struct{}{}:
+			default:
+			}
+		}
+	default:
+		atomic.AddInt64(&q.dropped, 1)
+	}
+}
+
+// Run sends queued spans every FlushInterval, or sooner if a full batch is
+// waiting, until ctx is canceled. Errors are passed to errs if it is non-nil.
+func (q *Queue) Run(ctx context.Context, errs func(error)) {
+	ticker := time.NewTicker(q.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.kick:
+		}
+		if err := q.Flush(ctx); err != nil && errs != nil {
+			errs(err)
+		}
+	}
+}
+
+// Flush sends all currently queued spans, in batches of at most BatchSize,
+// retrying each batch with exponential backoff while send says to. The spans
+// of a batch that can't be sent are counted as failed.
+func (q *Queue) Flush(ctx context.Context) error {
+	q.flushMtx.Lock()
+	defer q.flushMtx.Unlock()
+
+	for {
+		batch := q.dequeue()
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := q.sendWithRetries(ctx, batch); err != nil {
+			atomic.AddInt64(&q.failed, int64(len(batch)))
+			return err
+		}
+		atomic.AddInt64(&q.sent, int64(len(batch)))
+	}
+}
+
+func (q *Queue) dequeue() (batch []*collect.FinishedSpan) {
+	for len(batch) < q.opts.BatchSize {
+		select {
+		case fs := <-q.queue:
+			batch = append(batch, fs)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (q *Queue) sendWithRetries(ctx context.Context, batch []*collect.FinishedSpan) error {
+	backoff := q.opts.MinBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := q.send(ctx, batch)
+		if err == nil || !retry || attempt >= q.opts.MaxRetries {
+			return err
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		backoff *= 2
+		if backoff > q.opts.MaxBackoff {
+			backoff = q.opts.MaxBackoff
+		}
+	}
+}
+
+// Stats reports under key how many spans have been sent (as the sentField
+// field), dropped because the queue was full, failed to send, or are queued.
+func (q *Queue) Stats(key monkit.SeriesKey, sentField string,
+	cb func(key monkit.SeriesKey, field string, val float64)) {
+	cb(key, sentField, float64(atomic.LoadInt64(&q.sent)))
+	cb(key, "dropped", float64(atomic.LoadInt64(&q.dropped)))
+	cb(key, "failed", float64(atomic.LoadInt64(&q.failed)))
+	cb(key, "queued", float64(len(q.queue)))
+}
+
+// Post sends body to url with the given headers and content type. Network
+// errors, 5xx responses and 429 responses are worth retrying. Errors from
+// responses are prefixed with name.
+func Post(ctx context.Context, client mhttp.Client, url string, header http.Header,
+	contentType string, body []byte, name string) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for key, vals := range header {
+		req.Header[key] = vals
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("%s: %s: %s", name, resp.Status, bytes.TrimSpace(msg))
+	return resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests, err
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanbatch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3/collect"
+)
+
+func TestQueue(t *testing.T) {
+	var batches []int
+	q := New(Options{BatchSize: 2, QueueSize: 5},
+		func(ctx context.Context, batch []*collect.FinishedSpan) (bool, error) {
+			batches = append(batches, len(batch))
+			return false, nil
+		})
+	for i := 0; i < 6; i++ {
+		q.Add(&collect.FinishedSpan{})
+	}
+	if err := q.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 3 || batches[0] != 2 || batches[2] != 1 {
+		t.Fatalf("unexpected batches %v", batches)
+	}
+}
+
+func TestQueueRetries(t *testing.T) {
+	attempts := 0
+	q := New(Options{MaxRetries: 2, MinBackoff: time.Millisecond},
+		func(ctx context.Context, batch []*collect.FinishedSpan) (bool, error) {
+			attempts++
+			return true, errors.New("unavailable")
+		})
+	q.Add(&collect.FinishedSpan{})
+	if err := q.Flush(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestQueueRunKick(t *testing.T) {
+	sent := make(chan int, 1)
+	q := New(Options{BatchSize: 2, FlushInterval: time.Hour},
+		func(ctx context.Context, batch []*collect.FinishedSpan) (bool, error) {
+			sent <- len(batch)
+			return false, nil
+		})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx, nil)
+
+	q.Add(&collect.FinishedSpan{})
+	q.Add(&collect.FinishedSpan{})
+	select {
+	case n := <-sent:
+		if n != 2 {
+			t.Fatalf("expected a full batch, got %d", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a full batch to be sent before the flush interval")
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3/monotime"
+)
+
+// ErrSpanLeaked is the error SpanObservers see when a LeakDetector finishes
+// a Span whose Task function was garbage collected.
+var ErrSpanLeaked = errors.New("monkit: span leaked")
+
+// maxSpanLeaks bounds the number of SpanLeaks a LeakDetector keeps.
+const maxSpanLeaks = 100
+
+// SpanLeakReason is why a LeakDetector considers a Span leaked.
+type SpanLeakReason string
+
+const (
+	// SpanCollected means the function returned by the Task that created
+	// the Span was garbage collected without being called, so the Span can
+	// never finish.
+	SpanCollected SpanLeakReason = "collected"
+
+	// SpanExpired means the Span has been running longer than the
+	// LeakDetector's TTL.
+	SpanExpired SpanLeakReason = "expired"
+)
+
+// SpanLeak describes a Span that was never finished.
+type SpanLeak struct {
+	Span     *Span
+	Reason   SpanLeakReason
+	Age      time.Duration
+	Detected time.Time
+
+	// Stack is where the Span was created.
+	Stack string
+}
+
+// LeakDetector finds Spans that are never finished, such as when the
+// function a Task returns is never called:
+//
+//	mon.Task()(&ctx) // missing (&err), so the span never finishes
+//
+// Every Registry has one, returned by Registry.LeakDetector, which does
+// nothing until Enable is called. Once enabled, every new Span records where
+// it was created, and leaks are found in two ways. Spans whose Task
+// function is garbage collected without being called are reported as soon as
+// the garbage collector notices, and are removed from the Registry and from
+// their Func's current count, since they can't finish anymore. SpanObservers
+// see them finish with ErrSpanLeaked. Spans running
+// longer than the TTL are reported by Check, but are left alone, since they
+// may still finish.
+//
+// Funcs with leaked Spans are reported in Stats with the "leaked_spans" field
+// on their "function" series. Each Span is counted once. The present package
+// lists recent leaks at /leaks.
+type LeakDetector struct {
+	r *Registry
+
+	mtx    sync.Mutex
+	ttl    time.Duration
+	leaks  []SpanLeak
+	seen   map[*Span]bool
+	counts map[*Func]int64
+}
+
+// LeakDetector returns the Registry's LeakDetector, which is shared with every
+// Registry returned by WithTransformers.
+func (r *Registry) LeakDetector() *LeakDetector {
+	r.leaksOnce.Do(func() {
+		r.leaks = &LeakDetector{
+			r:      r,
+			seen:   map[*Span]bool{},
+			counts: map[*Func]int64{},
+		}
+	})
+	return r.leaks
+}
+
+// Enable starts tracking new Spans, with the given TTL for Check. A TTL of
+// zero only detects garbage collected Spans. Tracking costs a stack capture
+// and a finalizer per Span.
+func (d *LeakDetector) Enable(ttl time.Duration) {
+	d.mtx.Lock()
+	d.ttl = ttl
+	d.mtx.Unlock()
+	atomic.StoreInt32(&d.r.trackLeaks, 1)
+}
+
+// Disable stops tracking new Spans. Spans already tracked are still reported
+// if they are garbage collected.
+func (d *LeakDetector) Disable() {
+	atomic.StoreInt32(&d.r.trackLeaks, 0)
+}
+
+// Run calls Check every interval until ctx is canceled.
+func (d *LeakDetector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		d.Check()
+	}
+}
+
+// Check reports tracked Spans running longer than the TTL that haven't been
+// reported yet, and returns all recent leaks, newest first.
+func (d *LeakDetector) Check() []SpanLeak {
+	d.mtx.Lock()
+	ttl := d.ttl
+	d.mtx.Unlock()
+
+	now := time.Now()
+	var expired []SpanLeak
+	running := map[*Span]bool{}
+	if ttl > 0 {
+		d.r.AllSpans(func(s *Span) {
+			if s.callers == nil {
+				return
+			}
+			running[s] = true
+			if age := s.Duration(); age > ttl {
+				expired = append(expired, SpanLeak{
+					Span:     s,
+					Reason:   SpanExpired,
+					Age:      age,
+					Detected: now,
+				})
+			}
+		})
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	for s := range d.seen {
+		if !running[s] {
+			delete(d.seen, s)
+		}
+	}
+	for _, leak := range expired {
+		if !d.seen[leak.Span] {
+			d.seen[leak.Span] = true
+			d.addLocked(leak)
+		}
+	}
+	return d.leaksLocked()
+}
+
+// Leaks returns the recent leaks, newest first.
+func (d *LeakDetector) Leaks() []SpanLeak {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	return d.leaksLocked()
+}
+
+func (d *LeakDetector) leaksLocked() []SpanLeak {
+	rv := make([]SpanLeak, 0, len(d.leaks))
+	for i := len(d.leaks) - 1; i >= 0; i-- {
+		rv = append(rv, d.leaks[i])
+	}
+	return rv
+}
+
+// addLocked records a leak, counting it unless its Span was reported
+// before.
+func (d *LeakDetector) addLocked(leak SpanLeak) {
+	leak.Stack = formatCallers(leak.Span.callers)
+	if len(d.leaks) >= maxSpanLeaks {
+		copy(d.leaks, d.leaks[1:])
+		d.leaks = d.leaks[:len(d.leaks)-1]
+	}
+	d.leaks = append(d.leaks, leak)
+	d.counts[leak.Span.f]++
+}
+
+// collected is called when the Task function of an unfinished Span is
+// garbage collected. ctx is the context the Task returned, which observers
+// get back in Finish.
+func (d *LeakDetector) collected(s *Span, ctx context.Context) {
+	s.mtx.Lock()
+	done := s.done
+	s.mtx.Unlock()
+	if done {
+		return
+	}
+
+	d.mtx.Lock()
+	leak := SpanLeak{
+		Span:     s,
+		Reason:   SpanCollected,
+		Age:      s.Duration(),
+		Detected: time.Now(),
+	}
+	if d.seen[s] {
+		// already counted when it expired.
+		delete(d.seen, s)
+		d.counts[s.f]--
+	}
+	d.addLocked(leak)
+	d.mtx.Unlock()
+
+	atomic.AddInt64(&s.f.current, -1)
+	s.unregister()
+
+	// let observers buffering the trace, like trace samplers, see the Span
+	// end.
+	if observer := s.trace.getObserver(); observer != nil {
+		observer.Finish(ctx, s, ErrSpanLeaked, false, monotime.Now())
+	}
+}
+
+// forget drops the leaked Span count of a removed Func.
+func (d *LeakDetector) forget(f *Func) {
+	d.mtx.Lock()
+	delete(d.counts, f)
+	d.mtx.Unlock()
+}
+
+// stats reports the leaked Span counts of the Funcs in the Scope.
+func (d *LeakDetector) stats(s *Scope, cb func(key SeriesKey, field string, val float64)) {
+	type funcCount struct {
+		f     *Func
+		count int64
+	}
+	var counts []funcCount
+	d.mtx.Lock()
+	for f, count := range d.counts {
+		if f.scope == s {
+			counts = append(counts, funcCount{f: f, count: count})
+		}
+	}
+	d.mtx.Unlock()
+	sort.Slice(counts, func(i, j int) bool { return counts[i].f.id < counts[j].f.id })
+
+	for _, c := range counts {
+		cb(c.f.key, "leaked_spans", float64(c.count))
+	}
+}
+
+// leakTracker is referenced only by the function a Task returns, so its
+// finalizer runs if that function is garbage collected.
+type leakTracker struct {
+	s   *Span
+	ctx context.Context
+}
+
+func trackLeak(s *Span) *leakTracker {
+	var pcs [32]uintptr
+	// skip runtime.Callers, trackLeak and newSpan.
+	s.callers = append([]uintptr(nil), pcs[:runtime.Callers(3, pcs[:])]...)
+	t := &leakTracker{s: s}
+	runtime.SetFinalizer(t, func(t *leakTracker) {
+		t.s.f.scope.r.LeakDetector().collected(t.s, t.ctx)
+	})
+	return t
+}
+
+func (t *leakTracker) finished() {
+	runtime.SetFinalizer(t, nil)
+}
+
+func formatCallers(callers []uintptr) string {
+	if len(callers) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(callers)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
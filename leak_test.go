@@ -0,0 +1,113 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeakDetector(t *testing.T) {
+	r := NewRegistry()
+	mon := r.ScopeNamed("test")
+	leaky, slow := mon.FuncNamed("leaky"), mon.FuncNamed("slow")
+	d := r.LeakDetector()
+	d.Enable(time.Millisecond)
+
+	finished := make(chan error, 1)
+	defer r.ObserveTraces(func(tr *Trace) {
+		tr.ObserveSpansCtx(finishObserver(func(ctx context.Context, s *Span, err error) {
+			if s.Func() != leaky {
+				return
+			}
+			if ctx.Value(finishObserverKey{}) != s {
+				err = errors.New("expected the context returned by Start")
+			}
+			finished <- err
+		}))
+	})()
+
+	func() {
+		ctx := context.Background()
+		leaky.Task(&ctx)
+	}()
+	for i := 0; i < 100 && len(d.Leaks()) == 0; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	leaks := d.Leaks()
+	if len(leaks) != 1 || leaks[0].Reason != SpanCollected || leaks[0].Span.Func() != leaky {
+		t.Fatalf("unexpected leaks: %+v", leaks)
+	}
+	if !strings.Contains(leaks[0].Stack, "TestLeakDetector") {
+		t.Fatalf("expected creation stack, got %q", leaks[0].Stack)
+	}
+	if leaky.Current() != 0 {
+		t.Fatalf("expected collected span to be released, current %d", leaky.Current())
+	}
+	r.RootSpans(func(s *Span) { t.Fatalf("unexpected running span %v", s) })
+	select {
+	case err := <-finished:
+		if err != ErrSpanLeaked {
+			t.Fatalf("expected ErrSpanLeaked, got %v", err)
+		}
+	default:
+		t.Fatal("expected observers to see the collected span finish")
+	}
+
+	ctx := context.Background()
+	done := slow.Task(&ctx)
+	time.Sleep(5 * time.Millisecond)
+	d.Check()
+	if leaks := d.Check(); len(leaks) != 2 || leaks[0].Reason != SpanExpired {
+		t.Fatalf("unexpected leaks: %+v", leaks)
+	}
+	done(nil)
+
+	stats := Collect(r)
+	if stats["function,name=leaky,scope=test leaked_spans"] != 1 ||
+		stats["function,name=slow,scope=test leaked_spans"] != 1 {
+		t.Fatalf("unexpected stats: %v", stats)
+	}
+
+	mon.RemoveFunc("leaky")
+	if _, ok := Collect(r)["function,name=leaky,scope=test leaked_spans"]; ok {
+		t.Fatal("expected no leaked spans reported for a removed func")
+	}
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if len(d.counts) != 1 {
+		t.Fatalf("expected the removed func to be forgotten: %v", d.counts)
+	}
+}
+
+// finishObserver marks the context of every Span it sees start, and calls
+// itself with the context it gets back when the Span finishes.
+type finishObserver func(ctx context.Context, s *Span, err error)
+
+type finishObserverKey struct{}
+
+func (f finishObserver) Start(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, finishObserverKey{}, s)
+}
+
+func (f finishObserver) Finish(ctx context.Context, s *Span, err error,
+	panicked bool, finish time.Time) {
+	f(ctx, s, err)
+}
@@ -198,6 +198,19 @@ func (t *ticker) register(m *Meter) {
 	t.mtx.Unlock()
 }
 
+func (t *ticker) unregister(m *Meter) {
+	t.mtx.Lock()
+	// copy rather than modify in place, since run reads the slice unlocked.
+	meters := make([]*Meter, 0, len(t.meters))
+	for _, other := range t.meters {
+		if other != m {
+			meters = append(meters, other)
+		}
+	}
+	t.meters = meters
+	t.mtx.Unlock()
+}
+
 func (t *ticker) run() {
 	for {
 		time.Sleep(timePerTick)
@@ -0,0 +1,56 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import "sync/atomic"
+
+// PprofLabelMode controls which runtime/pprof labels Tasks set on their
+// goroutine. See Registry.SetPprofLabels.
+type PprofLabelMode int32
+
+const (
+	// NoPprofLabels sets no labels. It is the default.
+	NoPprofLabels PprofLabelMode = iota
+
+	// FuncPprofLabels sets the "monkit_func" label to the Func.FullName of the
+	// running Span.
+	FuncPprofLabels
+
+	// TracePprofLabels sets the "monkit_func" label, as well as the
+	// "trace_id" label to the id of the running Span's Trace, in hex.
+	TracePprofLabels
+)
+
+// SetPprofLabels makes Tasks on the Registry, and on every Registry returned by
+// WithTransformers, set runtime/pprof labels on the calling goroutine for the
+// lifetime of each Span, restoring the labels of the parent context when the
+// Span finishes. CPU and goroutine profiles can then be filtered by the same
+// Func names shown in the present package's /funcs output, for instance
+// with:
+//
+//	go tool pprof -tagfocus=monkit_func=mypkg.MyFunc cpu.prof
+//
+// The labels are also attached to the context a Task returns, so goroutines
+// started with pprof.Do or pprof.SetGoroutineLabels from it keep them.
+// Labeling has a small cost per Span, and has no effect when built with
+// TinyGo.
+func (r *Registry) SetPprofLabels(mode PprofLabelMode) {
+	atomic.StoreInt32(&r.pprofLabels, int32(mode))
+}
+
+// PprofLabels returns the mode set with SetPprofLabels.
+func (r *Registry) PprofLabels() PprofLabelMode {
+	return PprofLabelMode(atomic.LoadInt32(&r.pprofLabels))
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !tinygo
+// +build !tinygo
+
+package monkit
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPprofLabels(t *testing.T) {
+	r := NewRegistry()
+	r.SetPprofLabels(TracePprofLabels)
+	mon := r.ScopeNamed("test")
+	parent, child := mon.FuncNamed("parent"), mon.FuncNamed("child")
+
+	ctx := context.Background()
+	defer parent.Task(&ctx)(nil)
+	if name, _ := pprof.Label(ctx, "monkit_func"); name != "test.parent" {
+		t.Fatalf("unexpected monkit_func label %q", name)
+	}
+	traceId := strconv.FormatUint(uint64(SpanFromCtx(ctx).Trace().Id()), 16)
+
+	childCtx := ctx
+	child.Task(&childCtx)(nil)
+	if name, _ := pprof.Label(childCtx, "monkit_func"); name != "test.child" {
+		t.Fatalf("unexpected monkit_func label %q", name)
+	}
+	if id, _ := pprof.Label(childCtx, "trace_id"); id != traceId {
+		t.Fatalf("expected trace_id %q, got %q", traceId, id)
+	}
+	if SpanFromCtx(childCtx).Func() != child {
+		t.Fatal("expected labeled context to carry the span")
+	}
+}
+
+// goroutineLabeled reports whether a goroutine has the given pprof label, as
+// shown by the goroutine profile.
+func goroutineLabeled(t *testing.T, key, val string) bool {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	return strings.Contains(buf.String(), strconv.Quote(key)+":"+strconv.Quote(val))
+}
+
+func TestPprofLabelsRestore(t *testing.T) {
+	r := NewRegistry()
+	r.SetPprofLabels(FuncPprofLabels)
+	f := r.ScopeNamed("test").FuncNamed("restore")
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("worker", "restore-test"))
+	pprof.SetGoroutineLabels(ctx)
+	defer pprof.SetGoroutineLabels(context.Background())
+
+	done := f.Task(&ctx)
+	if !goroutineLabeled(t, "monkit_func", "test.restore") ||
+		!goroutineLabeled(t, "worker", "restore-test") {
+		t.Fatal("expected the span labels on the goroutine")
+	}
+	done(nil)
+	if goroutineLabeled(t, "monkit_func", "test.restore") ||
+		!goroutineLabeled(t, "worker", "restore-test") {
+		t.Fatal("expected the parent context labels to be restored")
+	}
+}
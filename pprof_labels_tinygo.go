@@ -0,0 +1,27 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build tinygo
+// +build tinygo
+
+package monkit
+
+import "context"
+
+// withPprofLabels does nothing, as TinyGo doesn't support runtime/pprof.
+func withPprofLabels(ctx context.Context, s *Span, mode PprofLabelMode) context.Context {
+	return ctx
+}
+
+func restorePprofLabels(ctx context.Context) {}
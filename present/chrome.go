@@ -0,0 +1,132 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+)
+
+// chromeEvent is an event in the Trace Event Format understood by Perfetto
+// and chrome://tracing.
+type chromeEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat,omitempty"`
+	Ph   string                 `json:"ph"`
+	Ts   float64                `json:"ts"`
+	Dur  float64                `json:"dur,omitempty"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+func chromeMicros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+// SpansToChromeTrace takes a list of FinishedSpans and writes them to w in the
+// Trace Event Format, which can be opened in Perfetto or chrome://tracing.
+// Every Span becomes a complete event, with times relative to the earliest
+// Span start. Each trace is a process, named after its hex id, and Spans are
+// laid out on threads using the same rows as SpansToSVG, so children appear
+// below their parents. Span arguments, annotations and errors are attached as
+// event args.
+func SpansToChromeTrace(w io.Writer, spans []*collect.FinishedSpan) error {
+	var minStart time.Time
+	for _, s := range spans {
+		if start := s.Span.Start(); minStart.IsZero() || start.Before(minStart) {
+			minStart = start
+		}
+	}
+	collect.StartTimeSorter(spans).Sort()
+	lis, _ := computeLayoutInformation(spans)
+
+	events := make([]chromeEvent, 0, len(spans)+1)
+	pids := map[int64]int{}
+	for _, s := range spans {
+		traceId := s.Span.Trace().Id()
+		pid, exists := pids[traceId]
+		if !exists {
+			pid = len(pids) + 1
+			pids[traceId] = pid
+			events = append(events, chromeEvent{
+				Name: "process_name",
+				Ph:   "M",
+				Pid:  pid,
+				Args: map[string]interface{}{
+					"name": "trace " + strconv.FormatUint(uint64(traceId), 16),
+				},
+			})
+		}
+
+		args := map[string]interface{}{
+			"span_id": s.Span.Id(),
+		}
+		if parentId, ok := s.Span.ParentId(); ok {
+			args["parent_id"] = parentId
+		}
+		if spanArgs := s.Span.Args(); len(spanArgs) > 0 {
+			args["args"] = strings.Join(spanArgs, ", ")
+		}
+		for _, annotation := range s.Span.Annotations() {
+			args[annotation.Name] = annotation.Value
+		}
+		if s.Err != nil {
+			args["error"] = s.Err.Error()
+		}
+		if s.Panicked {
+			args["panicked"] = true
+		}
+
+		events = append(events, chromeEvent{
+			Name: s.Span.Func().FullName(),
+			Cat:  s.Span.Func().Scope().Name(),
+			Ph:   "X",
+			Ts:   chromeMicros(s.Span.Start().Sub(minStart)),
+			Dur:  chromeMicros(s.Finish.Sub(s.Span.Start())),
+			Pid:  pid,
+			Tid:  lis[s.Span.Id()].Row,
+			Args: args,
+		})
+	}
+
+	return json.NewEncoder(w).Encode(struct {
+		TraceEvents     []chromeEvent `json:"traceEvents"`
+		DisplayTimeUnit string        `json:"displayTimeUnit"`
+	}{
+		TraceEvents:     events,
+		DisplayTimeUnit: "ms",
+	})
+}
+
+// TraceQueryChrome uses WatchForSpans to write all Spans from 'reg' matching
+// 'matcher' to 'w' in the Trace Event Format. See SpansToChromeTrace.
+func TraceQueryChrome(reg *monkit.Registry, w io.Writer,
+	matcher func(*monkit.Span) bool) error {
+	spans, err := watchForSpansWithKeepalive(context.TODO(),
+		reg, w, matcher, []byte("\n"))
+	if err != nil {
+		return err
+	}
+
+	return SpansToChromeTrace(w, spans)
+}
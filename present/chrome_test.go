@@ -0,0 +1,64 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+)
+
+func TestSpansToChromeTrace(t *testing.T) {
+	r := monkit.NewRegistry()
+	fr, stop := collect.StartFlightRecorder(r, collect.FlightRecorderOptions{})
+	defer stop()
+
+	mon := r.ScopeNamed("test")
+	func() {
+		ctx := context.Background()
+		defer mon.FuncNamed("parent").Task(&ctx)(nil)
+		err := errors.New("failed")
+		func() {
+			defer mon.FuncNamed("child").Task(&ctx)(&err)
+			monkit.SpanFromCtx(ctx).Annotate("key", "value")
+		}()
+	}()
+
+	var buf bytes.Buffer
+	if err := SpansToChromeTrace(&buf, fr.Recent()[0].Spans); err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		TraceEvents []chromeEvent `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.TraceEvents) != 3 || out.TraceEvents[0].Ph != "M" {
+		t.Fatalf("unexpected events: %+v", out.TraceEvents)
+	}
+	parent, child := out.TraceEvents[1], out.TraceEvents[2]
+	if parent.Name != "test.parent" || child.Name != "test.child" || child.Ph != "X" {
+		t.Fatalf("unexpected events: %+v", out.TraceEvents)
+	}
+	if child.Tid <= parent.Tid || child.Args["key"] != "value" || child.Args["error"] != "failed" {
+		t.Fatalf("unexpected child event: %+v", child)
+	}
+}
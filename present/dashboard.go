@@ -0,0 +1,32 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	_ "embed"
+	"io"
+)
+
+// dashboardHTML is the self-contained dashboard served at /. It only uses
+// relative paths to the other endpoints, so it works wherever the handler is
+// mounted.
+//
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+func writeDashboard(w io.Writer) error {
+	_, err := w.Write(dashboardHTML)
+	return err
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+func TestDashboard(t *testing.T) {
+	r := monkit.NewRegistry()
+	ctx := context.Background()
+	r.ScopeNamed("test").FuncNamed("f").Task(&ctx)(nil)
+
+	render := func(path string) (string, string) {
+		u, err := url.Parse(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, contentType, err := FromRequest(r, u.Path, u.Query())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := f(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String(), contentType
+	}
+
+	page, contentType := render("/")
+	if !strings.HasPrefix(contentType, "text/html") || !strings.Contains(page, "<script>") {
+		t.Fatalf("unexpected dashboard %q:\n%s", contentType, page)
+	}
+	if strings.Contains(page, "http://") || strings.Contains(page, "https://") ||
+		strings.Contains(page, "<script src") {
+		t.Fatal("dashboard should not load external resources")
+	}
+
+	// every JSON endpoint the dashboard fetches must exist and be valid
+	fetches := regexp.MustCompile(`getJSON\("([^"]+)"\)`).FindAllStringSubmatch(page, -1)
+	if len(fetches) == 0 {
+		t.Fatal("expected the dashboard to fetch JSON endpoints")
+	}
+	for _, m := range fetches {
+		out, _ := render("/" + m[1])
+		var v []interface{}
+		if err := json.Unmarshal([]byte(out), &v); err != nil {
+			t.Fatalf("%s: %v", m[1], err)
+		}
+	}
+
+	funcs, _ := render("/funcs/json")
+	if !strings.Contains(funcs, `"0.50"`) || strings.Contains(funcs, `"0.99"`) {
+		t.Fatalf("expected only the observed quantiles in funcs json:\n%s", funcs)
+	}
+	funcs, _ = render("/funcs/json?quantiles=0.99,0.999")
+	if !strings.Contains(funcs, `"0.99"`) || !strings.Contains(funcs, `"0.999"`) {
+		t.Fatalf("expected the requested quantiles in funcs json:\n%s", funcs)
+	}
+	if _, _, err := FromRequest(r, "/funcs/json", url.Values{"quantiles": {"2"}}); err == nil {
+		t.Fatal("expected an error for an invalid quantile")
+	}
+}
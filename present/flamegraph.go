@@ -0,0 +1,158 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	flameRowHeight = 16
+	flameFontSize  = 11
+	flameCharWidth = 7
+)
+
+type flameNode struct {
+	name     string
+	value    float64
+	children map[string]*flameNode
+}
+
+func (n *flameNode) child(name string) *flameNode {
+	if n.children == nil {
+		n.children = map[string]*flameNode{}
+	}
+	child := n.children[name]
+	if child == nil {
+		child = &flameNode{name: name}
+		n.children[name] = child
+	}
+	return child
+}
+
+func (n *flameNode) depth() (depth int) {
+	for _, child := range n.children {
+		if d := child.depth() + 1; d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+func flameColor(name string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	sum := h.Sum32()
+	return fmt.Sprintf("rgb(%d,%d,%d)", 205+sum%50, (sum/50)%230, (sum/11500)%55)
+}
+
+// writeFlameGraph renders stacks as a self-contained SVG flame graph, with
+// roots at the bottom, callees stacked above their callers, and frames of
+// the same parent sorted by name. Hovering a frame shows its full name and
+// weight.
+func writeFlameGraph(w io.Writer, stacks foldedStacks, weight FoldedWeight) error {
+	root := &flameNode{name: "all"}
+	for _, stack := range stacks.sorted() {
+		val := stacks[stack]
+		if val <= 0 {
+			continue
+		}
+		root.value += val
+		node := root
+		for _, frame := range strings.Split(stack, ";") {
+			node = node.child(frame)
+			node.value += val
+		}
+	}
+
+	unit := "µs"
+	if weight == FoldedCalls {
+		unit = "calls"
+	}
+	height := (root.depth() + 1) * flameRowHeight
+
+	_, err := fmt.Fprintf(w, `<svg version="1.1" xmlns="http://www.w3.org/2000/svg"
+  viewBox="0 0 %d %d" width="%d" height="%d">
+  <style type="text/css">
+    text { font-family: monospace; font-size: %dpx; pointer-events: none; }
+    rect { stroke: white; stroke-width: 0.5; }
+    rect:hover { stroke: black; }
+  </style>
+`, graphWidth, height, graphWidth, height, flameFontSize)
+	if err != nil {
+		return err
+	}
+
+	var draw func(n *flameNode, x float64, depth int) error
+	draw = func(n *flameNode, x float64, depth int) error {
+		width := float64(graphWidth)
+		if root.value > 0 {
+			width = n.value / root.value * graphWidth
+		}
+		if width < 0.5 {
+			return nil
+		}
+		y := height - (depth+1)*flameRowHeight
+		label := fmt.Sprintf("%s (%.0f %s, %.2f%%)", n.name, n.value, unit,
+			100*n.value/root.value)
+		_, err := fmt.Fprintf(w,
+			`  <g><title>%s</title><rect x="%.1f" y="%d" width="%.1f" height="%d" fill="%s"/>`,
+			html.EscapeString(label), x, y, width, flameRowHeight, flameColor(n.name))
+		if err != nil {
+			return err
+		}
+		if chars := int(width/flameCharWidth) - 1; chars >= 3 {
+			text := []rune(n.name)
+			if len(text) > chars {
+				text = append(text[:chars-2], '.', '.')
+			}
+			_, err = fmt.Fprintf(w, `<text x="%.1f" y="%d">%s</text>`,
+				x+3, y+flameRowHeight-4, html.EscapeString(string(text)))
+			if err != nil {
+				return err
+			}
+		}
+		if _, err = fmt.Fprintln(w, "</g>"); err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(n.children))
+		for name := range n.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := n.children[name]
+			if err := draw(child, x, depth+1); err != nil {
+				return err
+			}
+			x += child.value / root.value * graphWidth
+		}
+		return nil
+	}
+	if root.value > 0 {
+		if err := draw(root, 0, 0); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(w, "</svg>")
+	return err
+}
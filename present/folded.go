@@ -0,0 +1,263 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+)
+
+// FoldedWeight chooses what the weights of folded stacks measure.
+type FoldedWeight string
+
+const (
+	// FoldedTime weighs stacks by the time spent in the leaf Func itself,
+	// excluding its children, in microseconds.
+	FoldedTime FoldedWeight = "time"
+
+	// FoldedCalls weighs stacks by the number of calls to the leaf Func.
+	FoldedCalls FoldedWeight = "calls"
+)
+
+func parseFoldedWeight(weight string) (FoldedWeight, error) {
+	switch FoldedWeight(weight) {
+	case "", FoldedTime:
+		return FoldedTime, nil
+	case FoldedCalls:
+		return FoldedCalls, nil
+	}
+	return "", errBadRequest.New("invalid weight %#v, expected time or calls", weight)
+}
+
+const (
+	maxFoldedDepth = 64
+	maxFoldedPaths = 64
+)
+
+// foldedStacks maps stacks, as frame names joined by semicolons with the
+// root first, to their weights.
+type foldedStacks map[string]float64
+
+func foldedFrame(f *monkit.Func) string {
+	return strings.NewReplacer(";", ":", "\n", " ").Replace(f.FullName())
+}
+
+func (stacks foldedStacks) sorted() []string {
+	keys := make([]string, 0, len(stacks))
+	for stack := range stacks {
+		keys = append(keys, stack)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (stacks foldedStacks) write(w io.Writer) error {
+	for _, stack := range stacks.sorted() {
+		weight := int64(math.Round(stacks[stack]))
+		if weight <= 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %d\n", stack, weight); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func foldedMicros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
+
+// funcsFolded approximates folded stacks from the Func call graph. Since
+// the graph doesn't say which caller is responsible for how much of a Func's
+// time, each Func's time and calls are split evenly between its callers, and
+// its self time is split evenly between its paths from an entry point.
+func funcsFolded(r *monkit.Registry, weight FoldedWeight) foldedStacks {
+	var funcs []*monkit.Func
+	parents := map[*monkit.Func][]*monkit.Func{}
+	totals := map[*monkit.Func]float64{}
+	r.Funcs(func(f *monkit.Func) {
+		funcs = append(funcs, f)
+		f.Parents(func(parent *monkit.Func) {
+			parents[f] = append(parents[f], parent)
+		})
+		st, ft := f.SuccessTimes(), f.FailureTimes()
+		if weight == FoldedCalls {
+			totals[f] = float64(st.Count + ft.Count)
+		} else {
+			totals[f] = foldedMicros(st.Sum + ft.Sum)
+		}
+	})
+
+	self := make(map[*monkit.Func]float64, len(funcs))
+	for _, f := range funcs {
+		self[f] += totals[f]
+		if weight == FoldedCalls {
+			continue
+		}
+		for _, parent := range parents[f] {
+			if parent != nil {
+				self[parent] -= totals[f] / float64(len(parents[f]))
+			}
+		}
+	}
+
+	stacks := foldedStacks{}
+	for _, f := range funcs {
+		if self[f] <= 0 {
+			continue
+		}
+		paths := funcPaths(f, parents)
+		for _, path := range paths {
+			stacks[path] += self[f] / float64(len(paths))
+		}
+	}
+	return stacks
+}
+
+// funcPaths returns up to maxFoldedPaths paths from entry points to f
+// through the call graph, as folded stacks.
+func funcPaths(f *monkit.Func, parents map[*monkit.Func][]*monkit.Func) (paths []string) {
+	onPath := map[*monkit.Func]bool{}
+	var frames []string
+	var walk func(f *monkit.Func)
+	walk = func(f *monkit.Func) {
+		if len(paths) >= maxFoldedPaths || onPath[f] || len(frames) >= maxFoldedDepth {
+			return
+		}
+		onPath[f] = true
+		frames = append(frames, foldedFrame(f))
+		for _, parent := range parents[f] {
+			if parent != nil {
+				walk(parent)
+				continue
+			}
+			path := make([]string, len(frames))
+			for i, frame := range frames {
+				path[len(frames)-1-i] = frame
+			}
+			paths = append(paths, strings.Join(path, ";"))
+		}
+		frames = frames[:len(frames)-1]
+		onPath[f] = false
+	}
+	walk(f)
+	if len(paths) == 0 {
+		// only reachable through cycles or unknown callers.
+		paths = append(paths, foldedFrame(f))
+	}
+	return paths
+}
+
+// spansFolded computes folded stacks from the Span trees in spans.
+func spansFolded(spans []*collect.FinishedSpan, weight FoldedWeight) foldedStacks {
+	byId := make(map[int64]*collect.FinishedSpan, len(spans))
+	for _, s := range spans {
+		byId[s.Span.Id()] = s
+	}
+	childTime := map[int64]time.Duration{}
+	for _, s := range spans {
+		if parentId, ok := s.Span.ParentId(); ok {
+			childTime[parentId] += s.Finish.Sub(s.Span.Start())
+		}
+	}
+
+	stacks := foldedStacks{}
+	for _, s := range spans {
+		var frames []string
+		for cur := s; cur != nil && len(frames) < maxFoldedDepth; {
+			frames = append(frames, foldedFrame(cur.Span.Func()))
+			parentId, ok := cur.Span.ParentId()
+			if !ok {
+				break
+			}
+			cur = byId[parentId]
+		}
+		for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+			frames[i], frames[j] = frames[j], frames[i]
+		}
+		stack := strings.Join(frames, ";")
+
+		if weight == FoldedCalls {
+			stacks[stack]++
+			continue
+		}
+		self := s.Finish.Sub(s.Span.Start()) - childTime[s.Span.Id()]
+		if self > 0 {
+			stacks[stack] += foldedMicros(self)
+		}
+	}
+	return stacks
+}
+
+// FuncsFolded writes the Func call graph known by Registry r to w in the
+// folded stack format used by flame graph tools, one "root;...;leaf weight"
+// line per stack. As the call graph doesn't record which caller is
+// responsible for how much of a Func's time or calls, they are split evenly
+// between its callers, so the stacks are an approximation. See
+// TraceQueryFolded for exact stacks of a single trace.
+func FuncsFolded(r *monkit.Registry, w io.Writer, weight FoldedWeight) error {
+	return funcsFolded(r, weight).write(w)
+}
+
+// SpansToFolded takes a list of FinishedSpans and writes their stacks to w in
+// the folded stack format used by flame graph tools.
+func SpansToFolded(w io.Writer, spans []*collect.FinishedSpan, weight FoldedWeight) error {
+	return spansFolded(spans, weight).write(w)
+}
+
+// TraceQueryFolded uses WatchForSpans to write all Spans from 'reg' matching
+// 'matcher' to 'w' in the folded stack format. See SpansToFolded.
+func TraceQueryFolded(reg *monkit.Registry, w io.Writer,
+	matcher func(*monkit.Span) bool, weight FoldedWeight) error {
+	spans, err := watchForSpansWithKeepalive(context.TODO(),
+		reg, w, matcher, []byte("\n"))
+	if err != nil {
+		return err
+	}
+	return SpansToFolded(w, spans, weight)
+}
+
+// FuncsFlameGraph writes the stacks of FuncsFolded to w as an SVG flame
+// graph.
+func FuncsFlameGraph(r *monkit.Registry, w io.Writer, weight FoldedWeight) error {
+	return writeFlameGraph(w, funcsFolded(r, weight), weight)
+}
+
+// SpansToFlameGraph writes the stacks of SpansToFolded to w as an SVG flame
+// graph.
+func SpansToFlameGraph(w io.Writer, spans []*collect.FinishedSpan, weight FoldedWeight) error {
+	return writeFlameGraph(w, spansFolded(spans, weight), weight)
+}
+
+// TraceQueryFlameGraph uses WatchForSpans to write all Spans from 'reg'
+// matching 'matcher' to 'w' as an SVG flame graph.
+func TraceQueryFlameGraph(reg *monkit.Registry, w io.Writer,
+	matcher func(*monkit.Span) bool, weight FoldedWeight) error {
+	spans, err := watchForSpansWithKeepalive(context.TODO(),
+		reg, w, matcher, []byte("\n"))
+	if err != nil {
+		return err
+	}
+	return SpansToFlameGraph(w, spans, weight)
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+)
+
+func TestFolded(t *testing.T) {
+	r := monkit.NewRegistry()
+	fr, stop := collect.StartFlightRecorder(r, collect.FlightRecorderOptions{})
+	defer stop()
+
+	mon := r.ScopeNamed("test")
+	leaf := mon.FuncNamed("leaf")
+	call := func(f *monkit.Func, ctx context.Context, calls int) {
+		defer f.Task(&ctx)(nil)
+		for i := 0; i < calls; i++ {
+			func(ctx context.Context) { defer leaf.Task(&ctx)(nil) }(ctx)
+		}
+	}
+	ctx := context.Background()
+	call(mon.FuncNamed("a"), ctx, 2)
+	call(mon.FuncNamed("b"), ctx, 1)
+
+	var buf bytes.Buffer
+	if err := FuncsFolded(r, &buf, FoldedCalls); err != nil {
+		t.Fatal(err)
+	}
+	// leaf has 3 calls, split evenly between its two callers.
+	if exp := "test.a 1\ntest.a;test.leaf 2\ntest.b 1\ntest.b;test.leaf 2\n"; buf.String() != exp {
+		t.Fatalf("expected:\n%s\ngot:\n%s", exp, buf.String())
+	}
+
+	buf.Reset()
+	if err := SpansToFolded(&buf, fr.Recent()[1].Spans, FoldedCalls); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "test.a 1\ntest.a;test.leaf 2\n"; buf.String() != exp {
+		t.Fatalf("expected:\n%s\ngot:\n%s", exp, buf.String())
+	}
+
+	buf.Reset()
+	if err := FuncsFlameGraph(r, &buf, FoldedCalls); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<title>test.leaf (2 calls, 30.00%)</title>") {
+		t.Fatalf("unexpected flame graph:\n%s", buf.String())
+	}
+}
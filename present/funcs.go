@@ -33,13 +33,38 @@ func formatDist(data *monkit.DurationDist, indent string) (result string) {
 	return result
 }
 
+// formatEdge returns the dot attributes for an edge with the given stats,
+// with a pen width scaled by its share of maxCalls.
+func formatEdge(e monkit.EdgeStats, maxCalls int64) string {
+	if e.Calls == 0 {
+		return ""
+	}
+	width := 1.0
+	if maxCalls > 0 {
+		width += 4 * float64(e.Calls) / float64(maxCalls)
+	}
+	return fmt.Sprintf(" [label=\"%s\", penwidth=%.02f]",
+		escapeDotLabel("calls: %d\nerrors: %d, panics: %d\navg: %s\n",
+			e.Calls, e.Errors, e.Panics, e.Times.FullAverage()), width)
+}
+
 // FuncsDot finds all of the Funcs known by Registry r and writes information
-// about them in the dot graphics file format to w.
+// about them in the dot graphics file format to w. Edges into Funcs using
+// UseEdges are labeled with the calls made along them, and drawn thicker the
+// more calls they carry.
 func FuncsDot(r *monkit.Registry, w io.Writer) (err error) {
 	_, err = fmt.Fprintf(w, "digraph G {\n node [shape=box];\n")
 	if err != nil {
 		return err
 	}
+	maxCalls := int64(0)
+	r.Funcs(func(f *monkit.Func) {
+		for _, e := range f.Edges() {
+			if e.Calls > maxCalls {
+				maxCalls = e.Calls
+			}
+		}
+	})
 	r.Funcs(func(f *monkit.Func) {
 		if err != nil {
 			return
@@ -92,18 +117,26 @@ func FuncsDot(r *monkit.Registry, w io.Writer) (err error) {
 			return
 		}
 
+		edges := map[*monkit.Func]monkit.EdgeStats{}
+		for _, e := range f.Edges() {
+			if !e.Overflow {
+				edges[e.Parent] = e
+			}
+		}
+
 		f.Parents(func(parent *monkit.Func) {
 			if err != nil {
 				return
 			}
+			attrs := formatEdge(edges[parent], maxCalls)
 			if parent != nil {
-				_, err = fmt.Fprintf(w, " f%d -> f%d;\n", parent.Id(), f.Id())
+				_, err = fmt.Fprintf(w, " f%d -> f%d%s;\n", parent.Id(), f.Id(), attrs)
 				if err != nil {
 					return
 				}
 			} else {
-				_, err = fmt.Fprintf(w, " r%d [label=\"entry\"];\n r%d -> f%d;\n",
-					f.Id(), f.Id(), f.Id())
+				_, err = fmt.Fprintf(w, " r%d [label=\"entry\"];\n r%d -> f%d%s;\n",
+					f.Id(), f.Id(), f.Id(), attrs)
 				if err != nil {
 					return
 				}
@@ -182,9 +215,15 @@ func FuncsText(r *monkit.Registry, w io.Writer) (err error) {
 // FuncsJSON finds all of the Funcs known by Registry r and writes information
 // about them in the JSON format to w.
 func FuncsJSON(r *monkit.Registry, w io.Writer) (err error) {
+	return funcsJSON(r, w, nil)
+}
+
+// funcsJSON is FuncsJSON, with extra quantiles reported for every duration
+// distribution.
+func funcsJSON(r *monkit.Registry, w io.Writer, quantiles []float64) (err error) {
 	lw := newListWriter(w)
 	r.Funcs(func(f *monkit.Func) {
-		lw.elem(formatFunc(f))
+		lw.elem(formatFunc(f, quantiles))
 	})
 	return lw.done()
 }
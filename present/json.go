@@ -18,6 +18,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spacemonkeygo/monkit/v3"
@@ -115,9 +117,48 @@ type durationStats struct {
 	Low              time.Duration            `json:"min"`
 	Recent           time.Duration            `json:"recent"`
 	Quantiles        map[string]time.Duration `json:"quantiles"`
+	Exemplars        *exemplarStats           `json:"exemplars,omitempty"`
 }
 
-func formatDuration(d *monkit.DurationDist, out *durationStats) {
+type exemplarJSON struct {
+	Value   time.Duration `json:"value"`
+	TraceId int64         `json:"trace_id"`
+	SpanId  int64         `json:"span_id"`
+	Time    int64         `json:"time"`
+}
+
+type exemplarStats struct {
+	Low    *exemplarJSON `json:"min,omitempty"`
+	High   *exemplarJSON `json:"max,omitempty"`
+	Recent *exemplarJSON `json:"recent,omitempty"`
+}
+
+func formatExemplar(ex *monkit.Exemplar) *exemplarJSON {
+	if ex == nil {
+		return nil
+	}
+	return &exemplarJSON{
+		Value:   time.Duration(ex.Value * float64(time.Second)),
+		TraceId: ex.TraceId,
+		SpanId:  ex.SpanId,
+		Time:    ex.Time.UnixNano(),
+	}
+}
+
+func formatExemplars(set monkit.ExemplarSet, out *durationStats) {
+	if set.Latest == nil {
+		return
+	}
+	out.Exemplars = &exemplarStats{
+		Low:    formatExemplar(set.Low),
+		High:   formatExemplar(set.High),
+		Recent: formatExemplar(set.Latest),
+	}
+}
+
+// formatDuration fills out with the stats of d, with the ObservedQuantiles
+// and any extra quantiles.
+func formatDuration(d *monkit.DurationDist, out *durationStats, extra []float64) {
 	out.Average = d.FullAverage()
 	out.FullAverage = d.FullAverage()
 	out.ReservoirAverage = d.ReservoirAverage()
@@ -125,14 +166,50 @@ func formatDuration(d *monkit.DurationDist, out *durationStats) {
 	out.Low = d.Low
 	out.Recent = d.Recent
 	out.Quantiles = make(map[string]time.Duration,
-		len(monkit.ObservedQuantiles))
+		len(monkit.ObservedQuantiles)+len(extra))
 	for _, quantile := range monkit.ObservedQuantiles {
-		name := fmt.Sprintf("%.02f", quantile)
-		out.Quantiles[name] = d.Query(quantile)
+		out.Quantiles[quantileName(quantile)] = d.Query(quantile)
 	}
+	for _, quantile := range extra {
+		out.Quantiles[quantileName(quantile)] = d.Query(quantile)
+	}
+}
+
+// quantileName formats quantile with two decimals, or more if needed.
+func quantileName(quantile float64) string {
+	name := fmt.Sprintf("%.02f", quantile)
+	if parsed, err := strconv.ParseFloat(name, 64); err == nil && parsed == quantile {
+		return name
+	}
+	return strconv.FormatFloat(quantile, 'f', -1, 64)
 }
 
-func formatFunc(f *monkit.Func) interface{} {
+// parseQuantiles parses a comma separated list of quantiles.
+func parseQuantiles(list string) (quantiles []float64, err error) {
+	if list == "" {
+		return nil, nil
+	}
+	for _, field := range strings.Split(list, ",") {
+		quantile, err := strconv.ParseFloat(field, 64)
+		if err != nil || !(quantile >= 0 && quantile <= 1) {
+			return nil, errBadRequest.New(
+				"invalid quantile %#v, expected a number from 0 to 1", field)
+		}
+		quantiles = append(quantiles, quantile)
+	}
+	return quantiles, nil
+}
+
+type edgeJSON struct {
+	ParentId *int64        `json:"parent_id"`
+	Overflow bool          `json:"overflow,omitempty"`
+	Calls    int64         `json:"calls"`
+	Errors   int64         `json:"errors"`
+	Panics   int64         `json:"panics"`
+	Times    durationStats `json:"times"`
+}
+
+func formatFunc(f *monkit.Func, quantiles []float64) interface{} {
 	js := struct {
 		Id           int64            `json:"id"`
 		ParentIds    []int64          `json:"parent_ids"`
@@ -146,6 +223,7 @@ func formatFunc(f *monkit.Func) interface{} {
 		Errors       map[string]int64 `json:"errors"`
 		SuccessTimes durationStats    `json:"success_times"`
 		FailureTimes durationStats    `json:"failure_times"`
+		Edges        []edgeJSON       `json:"edges"`
 	}{}
 
 	js.Id = f.Id()
@@ -163,8 +241,20 @@ func formatFunc(f *monkit.Func) interface{} {
 	js.Success = f.Success()
 	js.Panics = f.Panics()
 	js.Errors = f.Errors()
-	formatDuration(f.SuccessTimes(), &js.SuccessTimes)
-	formatDuration(f.FailureTimes(), &js.FailureTimes)
+	formatDuration(f.SuccessTimes(), &js.SuccessTimes, quantiles)
+	formatDuration(f.FailureTimes(), &js.FailureTimes, quantiles)
+	formatExemplars(f.SuccessExemplars(), &js.SuccessTimes)
+	formatExemplars(f.FailureExemplars(), &js.FailureTimes)
+	for _, e := range f.Edges() {
+		edge := edgeJSON{Overflow: e.Overflow, Calls: e.Calls, Errors: e.Errors,
+			Panics: e.Panics}
+		if e.Parent != nil {
+			id := e.Parent.Id()
+			edge.ParentId = &id
+		}
+		formatDuration(e.Times, &edge.Times, quantiles)
+		js.Edges = append(js.Edges, edge)
+	}
 	return js
 }
 
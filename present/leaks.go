@@ -0,0 +1,49 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+// LeaksText runs a check of the Registry's LeakDetector and writes the recent
+// leaked Spans to w in a plain text format, newest first, with the stacks
+// where they were created.
+func LeaksText(r *monkit.Registry, w io.Writer) (err error) {
+	leaks := r.LeakDetector().Check()
+	if len(leaks) == 0 {
+		_, err = fmt.Fprintln(w, "no leaked spans")
+		return err
+	}
+	for _, leak := range leaks {
+		s := leak.Span
+		_, err = fmt.Fprintf(w, "[%d,%d] %s(%s) (%s after %s, at %s)\n",
+			s.Id(), s.Trace().Id(), s.Func().FullName(), strings.Join(s.Args(), ", "),
+			leak.Reason, leak.Age, leak.Detected.Format("2006-01-02T15:04:05.000Z07:00"))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "  %s\n\n",
+			strings.ReplaceAll(strings.TrimSpace(leak.Stack), "\n", "\n  "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
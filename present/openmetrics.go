@@ -0,0 +1,123 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+type openMetricsStat struct {
+	key   monkit.SeriesKey
+	field string
+	val   float64
+}
+
+// StatsOpenMetrics writes all of the name/value statistics pairs the Registry
+// knows to w in the OpenMetrics text format. Measurements reporting "bucket"
+// fields, such as Histograms, become histogram families, with the exemplars
+// the Registry knows attached to their buckets. Other stats are named as in
+// StatsPrometheus, but as OpenMetrics requires counters to end in "_total",
+// only fields named "total" are reported as counters, and the remaining
+// counter fields are reported as gauges.
+func StatsOpenMetrics(r *monkit.Registry, w io.Writer) (err error) {
+	exemplars := map[string]monkit.Exemplar{}
+	r.Exemplars(func(key monkit.SeriesKey, field string, ex monkit.Exemplar) {
+		exemplars[key.WithField(field)] = ex
+	})
+
+	var stats []openMetricsStat
+	histograms := map[string]bool{}
+	r.Stats(func(key monkit.SeriesKey, field string, val float64) {
+		stats = append(stats, openMetricsStat{key: key, field: field, val: val})
+		if field == "bucket" {
+			histograms[key.Measurement] = true
+		}
+	})
+
+	families := map[string]*prometheusFamily{}
+	addSample := func(family, typ, name, field string, key monkit.SeriesKey, val float64, suffix string) {
+		f := families[family]
+		if f == nil {
+			f = &prometheusFamily{typ: typ}
+			families[family] = f
+		}
+		f.samples = append(f.samples,
+			name+formatPrometheusLabels(field, key.Tags)+" "+formatPrometheusValue(val)+suffix+"\n")
+	}
+
+	for _, stat := range stats {
+		if histograms[stat.key.Measurement] {
+			family := sanitizePrometheusName(stat.key.Measurement, true)
+			switch stat.field {
+			case "bucket":
+				suffix := ""
+				if ex, ok := exemplars[stat.key.WithField(stat.field)]; ok {
+					suffix = formatOpenMetricsExemplar(ex)
+				}
+				addSample(family, "histogram", family+"_bucket", stat.field, stat.key, stat.val, suffix)
+				continue
+			case "count", "sum":
+				addSample(family, "histogram", family+"_"+stat.field, stat.field, stat.key, stat.val, "")
+				continue
+			}
+		}
+
+		name := PrometheusMetricName(stat.key.Measurement, stat.field)
+		family, typ := name, PrometheusType(stat.field)
+		switch {
+		case typ == "counter" && strings.HasSuffix(name, "_total"):
+			family = strings.TrimSuffix(name, "_total")
+		case typ == "counter":
+			typ = "gauge"
+		case typ == "untyped":
+			typ = "unknown"
+		}
+		addSample(family, typ, name, stat.field, stat.key, stat.val, "")
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		family := families[name]
+		_, err = io.WriteString(w, "# TYPE "+name+" "+family.typ+"\n")
+		if err != nil {
+			return err
+		}
+		for _, sample := range family.samples {
+			_, err = io.WriteString(w, sample)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	_, err = io.WriteString(w, "# EOF\n")
+	return err
+}
+
+func formatOpenMetricsExemplar(ex monkit.Exemplar) string {
+	return ` # {trace_id="` + strconv.FormatUint(uint64(ex.TraceId), 16) +
+		`",span_id="` + strconv.FormatUint(uint64(ex.SpanId), 16) + `"} ` +
+		formatPrometheusValue(ex.Value) + " " +
+		strconv.FormatFloat(float64(ex.Time.UnixNano())/1e9, 'f', -1, 64)
+}
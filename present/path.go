@@ -51,20 +51,47 @@ func curry(reg *monkit.Registry,
 // path, and optional query parameters, and returns a Result if possible.
 //
 // FromRequest understands the following paths:
+//  * /                   - returns an HTML dashboard of running spans, Funcs
+//                          and stats, which can also capture traces
 //  * /ps, /ps/text       - returns the result of SpansText
 //  * /ps/dot             - returns the result of SpansDot
 //  * /ps/json            - returns the result of SpansJSON
 //  * /funcs, /funcs/text - returns the result of FuncsText
 //  * /funcs/dot          - returns the result of FuncsDot
-//  * /funcs/json         - returns the result of FuncsJSON
+//  * /funcs/json         - returns the result of FuncsJSON, plus the
+//                          comma separated quantiles query parameter
+//  * /funcs/folded       - returns the result of FuncsFolded
+//  * /funcs/flamegraph   - returns the result of FuncsFlameGraph
 //  * /stats, /stats/text - returns the result of StatsText
 //  * /stats/json         - returns the result of StatsJSON
+//  * /stats/prometheus   - returns the result of StatsPrometheus
+//  * /stats/openmetrics  - returns the result of StatsOpenMetrics
+//  * /stats/exemplars    - returns the result of StatsExemplars
+//  * /stats/cardinality  - returns the result of StatsCardinality
+//  * /stuck, /stuck/text - returns the result of StuckText
+//  * /stuck/json         - returns the result of StuckJSON
+//  * /leaks              - returns the result of LeaksText
+//  * /traces/recent      - returns the result of RecordedTracesText
+//  * /traces/recent/json - returns the result of RecordedTracesJSON, or
+//                          SpansToJSON of the trace_id query parameter
+//  * /traces/recent/svg  - returns SpansToSVG of the trace_id query parameter
+//  * /traces/recent/chrome - returns SpansToChromeTrace of the trace_id query
+//                            parameter
 //  * /trace/svg          - returns the result of TraceQuerySVG
 //  * /trace/json         - returns the result of TraceQueryJSON
+//  * /trace/chrome       - returns the result of TraceQueryChrome
+//  * /trace/folded       - returns the result of TraceQueryFolded
+//  * /trace/flamegraph   - returns the result of TraceQueryFlameGraph
 //  * /trace/remote       - returns trace id or redirect
 //
-// The last two paths are worth discussing in more detail, as they take
-// query parameters. All trace endpoints require at least one of the following
+// The folded and flamegraph paths take an optional weight query parameter,
+// either time (the default) or calls. See FoldedWeight.
+//
+// The /traces/recent paths require a FlightRecorder started for the Registry
+// with collect.StartFlightRecorder, and take trace ids in hex.
+//
+// The /trace paths are worth discussing in more detail, as they take
+// query parameters. All /trace endpoints require at least one of the following
 // two query parameters:
 //  * regex    - If provided, the very next Span that crosses a Func that has
 //               a name that matches this regex will start a trace until that
@@ -104,7 +131,7 @@ func FromRequest(reg *monkit.Registry, path string, query url.Values) (
 	second, _ := shift(rest)
 	switch first {
 	case "":
-		return writeIndex, "text/html", nil
+		return writeDashboard, "text/html; charset=utf-8", nil
 	case "ps":
 		switch second {
 		case "", "text":
@@ -122,7 +149,29 @@ func FromRequest(reg *monkit.Registry, path string, query url.Values) (
 		case "dot":
 			return curry(reg, FuncsDot), "text/plain; charset=utf-8", nil
 		case "json":
-			return curry(reg, FuncsJSON), "application/json; charset=utf-8", nil
+			quantiles, err := parseQuantiles(query.Get("quantiles"))
+			if err != nil {
+				return nil, "", err
+			}
+			return func(w io.Writer) error {
+				return funcsJSON(reg, w, quantiles)
+			}, "application/json; charset=utf-8", nil
+		case "folded":
+			weight, err := parseFoldedWeight(query.Get("weight"))
+			if err != nil {
+				return nil, "", err
+			}
+			return func(w io.Writer) error {
+				return FuncsFolded(reg, w, weight)
+			}, "text/plain; charset=utf-8", nil
+		case "flamegraph":
+			weight, err := parseFoldedWeight(query.Get("weight"))
+			if err != nil {
+				return nil, "", err
+			}
+			return func(w io.Writer) error {
+				return FuncsFlameGraph(reg, w, weight)
+			}, "image/svg+xml; charset=utf-8", nil
 		}
 
 	case "stats":
@@ -135,6 +184,85 @@ func FromRequest(reg *monkit.Registry, path string, query url.Values) (
 			return func(w io.Writer) error {
 				return StatsJSON(reg, w)
 			}, "application/json; charset=utf-8", nil
+		case "prometheus":
+			return func(w io.Writer) error {
+				return StatsPrometheus(reg, w)
+			}, "text/plain; version=0.0.4; charset=utf-8", nil
+		case "openmetrics":
+			return curry(reg, StatsOpenMetrics),
+				"application/openmetrics-text; version=1.0.0; charset=utf-8", nil
+		case "exemplars":
+			return curry(reg, StatsExemplars), "text/plain; charset=utf-8", nil
+		case "cardinality":
+			return curry(reg, StatsCardinality), "text/plain; charset=utf-8", nil
+		}
+
+	case "stuck":
+		switch second {
+		case "", "text":
+			return curry(reg, StuckText), "text/plain; charset=utf-8", nil
+		case "json":
+			return curry(reg, StuckJSON), "application/json; charset=utf-8", nil
+		}
+
+	case "leaks":
+		if second == "" {
+			return curry(reg, LeaksText), "text/plain; charset=utf-8", nil
+		}
+
+	case "traces":
+		if second != "recent" {
+			break
+		}
+		fr := collect.FlightRecorderFor(reg)
+		if fr == nil {
+			return nil, "", errNotFound.New("no flight recorder started; " +
+				"see collect.StartFlightRecorder")
+		}
+		_, rest = shift(rest)
+		third, _ := shift(rest)
+		var rt *collect.RecordedTrace
+		if traceIdStr := query.Get("trace_id"); traceIdStr != "" {
+			traceId, err := strconv.ParseUint(traceIdStr, 16, 64)
+			if err != nil {
+				return nil, "", errBadRequest.New(
+					"trace_id expected to be hex unsigned 64 bit number: %#v", traceIdStr)
+			}
+			rt = fr.Find(int64(traceId))
+			if rt == nil {
+				return nil, "", errNotFound.New("trace %x not recorded", traceId)
+			}
+		}
+		switch third {
+		case "", "text":
+			return func(w io.Writer) error {
+				return RecordedTracesText(fr, w)
+			}, "text/plain; charset=utf-8", nil
+		case "json":
+			if rt == nil {
+				return func(w io.Writer) error {
+					return RecordedTracesJSON(fr, w)
+				}, "application/json; charset=utf-8", nil
+			}
+			return func(w io.Writer) error {
+				return SpansToJSON(w, rt.Spans)
+			}, "application/json; charset=utf-8", nil
+		case "svg":
+			if rt == nil {
+				return nil, "", errBadRequest.New("trace_id query parameter required")
+			}
+			return func(w io.Writer) error {
+				// SpansToSVG sorts the spans, and kept traces are shared.
+				return SpansToSVG(w, append([]*collect.FinishedSpan(nil), rt.Spans...))
+			}, "image/svg+xml; charset=utf-8", nil
+		case "chrome":
+			if rt == nil {
+				return nil, "", errBadRequest.New("trace_id query parameter required")
+			}
+			return func(w io.Writer) error {
+				// SpansToChromeTrace sorts the spans, and kept traces are shared.
+				return SpansToChromeTrace(w, append([]*collect.FinishedSpan(nil), rt.Spans...))
+			}, "application/json; charset=utf-8", nil
 		}
 
 	case "trace":
@@ -201,6 +329,23 @@ func FromRequest(reg *monkit.Registry, path string, query url.Values) (
 			return func(w io.Writer) error {
 				return TraceQueryJSON(reg, w, spanMatcher)
 			}, "application/json; charset=utf-8", nil
+		case "chrome":
+			return func(w io.Writer) error {
+				return TraceQueryChrome(reg, w, spanMatcher)
+			}, "application/json; charset=utf-8", nil
+		case "folded", "flamegraph":
+			weight, err := parseFoldedWeight(query.Get("weight"))
+			if err != nil {
+				return nil, "", err
+			}
+			if second == "folded" {
+				return func(w io.Writer) error {
+					return TraceQueryFolded(reg, w, spanMatcher, weight)
+				}, "text/plain; charset=utf-8", nil
+			}
+			return func(w io.Writer) error {
+				return TraceQueryFlameGraph(reg, w, spanMatcher, weight)
+			}, "image/svg+xml; charset=utf-8", nil
 		case "remote":
 			viz := query.Get("viz")
 			if viz != "" && (!strings.HasPrefix(viz, "http:") && !strings.HasPrefix(viz, "https:")) {
@@ -288,36 +433,3 @@ func shift(path string) (dir, left string) {
 	}
 	return path[:split], path[split:]
 }
-
-func writeIndex(w io.Writer) error {
-	_, err := w.Write([]byte(`<!DOCTYPE html>
-<html>
-	<head>
-		<meta charset="utf-8">
-		<title>Monkit</title>
-	</head>
-	<body>
-		<dl style="max-width: 80ch;">
-			<dt><a href="ps">/ps</a></dt>
-			<dt><a href="ps/json">/ps/json</a></dt>
-			<dt><a href="ps/dot">/ps/dot</a></dt>
-			<dd>Information about active spans.</dd>
-
-			<dt><a href="funcs">/funcs</a></dt>
-			<dt><a href="funcs/json">/funcs/json</a></dt>
-			<dt><a href="funcs/dot">/funcs/dot</a></dt>
-			<dd>Information about the functions and their relations.</dd>
-
-			<dt><a href="stats">/stats</a></dt>
-			<dt><a href="stats/json">/stats/json</a></dt>
-			<dt><a href="stats/svg">/stats/svg</a></dt>
-			<dd>Statistics about all observed functions, scopes and values.</dd>
-
-			<dt><a href="trace/json">/trace/json</a></dt>
-			<dt><a href="trace/svg">/trace/svg</a></dt>
-			<dd>Trace the next scope that matches one of the <code>?regex=</code> or <code>?trace_id=</code> query arguments. By default, regular expressions are matched ahead of time against all known Funcs, but perhaps the Func you want to trace hasn't been observed by the process yet, in which case the regex will fail to match anything. You can turn off this preselection behavior by providing <code>&preselect=false</code> as an additional query param. Be advised that until a trace completes, whether or not it has started, it adds a small amount of overhead (a comparison or two) to every monitored function.</dd>
-		</dl>
-	</body>
-</html>`))
-	return err
-}
@@ -0,0 +1,253 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+// prometheusTypes maps monkit field names to Prometheus metric types. Fields
+// not found here are reported as untyped.
+var prometheusTypes = map[string]string{
+	"total":     "counter",
+	"count":     "counter",
+	"successes": "counter",
+	"errors":    "counter",
+	"panics":    "counter",
+	"failures":  "counter",
+	"true":      "counter",
+	"false":     "counter",
+	"bucket":    "counter",
+
+	"current":     "gauge",
+	"highwater":   "gauge",
+	"value":       "gauge",
+	"high":        "gauge",
+	"low":         "gauge",
+	"rate":        "gauge",
+	"recent":      "gauge",
+	"min":         "gauge",
+	"max":         "gauge",
+	"disposition": "gauge",
+}
+
+// PrometheusType returns the Prometheus metric type ("counter", "gauge" or
+// "untyped") that best describes values of the given monkit field.
+func PrometheusType(field string) string {
+	if typ, ok := prometheusTypes[field]; ok {
+		return typ
+	}
+	return "untyped"
+}
+
+// PrometheusMetricName turns a SeriesKey measurement and field into a valid
+// Prometheus metric name, replacing any disallowed characters with
+// underscores.
+func PrometheusMetricName(measurement, field string) string {
+	return sanitizePrometheusName(measurement+"_"+field, true)
+}
+
+// PrometheusLabelName turns a tag key into a valid Prometheus label name.
+// Names starting with "__" are reserved for Prometheus, such as "__name__",
+// so they are prefixed with "exported_".
+func PrometheusLabelName(key string) string {
+	name := sanitizePrometheusName(key, false)
+	if strings.HasPrefix(name, "__") {
+		return "exported_" + name
+	}
+	return name
+}
+
+// prometheusLabelName is PrometheusLabelName, also renaming "le", which is
+// reserved for the bucket label of histograms.
+func prometheusLabelName(key string) string {
+	if key == "le" {
+		return "exported_le"
+	}
+	return PrometheusLabelName(key)
+}
+
+// PrometheusLabelNames turns the tag keys of one series into distinct, valid
+// Prometheus label names, returned in the same order. Keys that are already
+// valid label names keep them. Other keys whose name is taken, such as "a-b"
+// next to "a_b", get the lowest free "_2", "_3", ... suffix, trying keys in
+// sorted order so the result doesn't depend on the order of keys. Reserved
+// names are renamed as by PrometheusLabelName, and "le" is renamed to
+// "exported_le"; callers writing the "bucket" field of a histogram should
+// name its "le" tag "le" instead, which no other key is renamed to.
+func PrometheusLabelNames(keys []string) []string {
+	names := make([]string, len(keys))
+	used := make(map[string]bool, len(keys))
+	for i, key := range keys {
+		if prometheusLabelName(key) == key {
+			names[i] = key
+			used[key] = true
+		}
+	}
+	order := make([]int, 0, len(keys))
+	for i := range keys {
+		if names[i] == "" {
+			order = append(order, i)
+		}
+	}
+	sort.Slice(order, func(a, b int) bool { return keys[order[a]] < keys[order[b]] })
+	for _, i := range order {
+		base := prometheusLabelName(keys[i])
+		name := base
+		for n := 2; used[name]; n++ {
+			name = base + "_" + strconv.Itoa(n)
+		}
+		names[i] = name
+		used[name] = true
+	}
+	return names
+}
+
+func sanitizePrometheusName(name string, allowColon bool) string {
+	if name == "" {
+		return "_"
+	}
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', c == '_':
+		case '0' <= c && c <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+		case c == ':' && allowColon:
+		default:
+			c = '_'
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// writePrometheusLabelValue writes val escaped per the Prometheus text
+// exposition format.
+func writePrometheusLabelValue(b *strings.Builder, val string) {
+	for i := 0; i < len(val); i++ {
+		switch val[i] {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteByte(val[i])
+		}
+	}
+}
+
+func formatPrometheusValue(val float64) string {
+	switch {
+	case math.IsNaN(val):
+		return "NaN"
+	case math.IsInf(val, 1):
+		return "+Inf"
+	case math.IsInf(val, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(val, 'g', -1, 64)
+}
+
+func formatPrometheusLabels(field string, tags *monkit.TagSet) string {
+	all := tags.All()
+	if len(all) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(all))
+	for key := range all {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	names := PrometheusLabelNames(keys)
+	if field == "bucket" {
+		for i, key := range keys {
+			if key == "le" {
+				names[i] = key
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(names[i])
+		b.WriteString(`="`)
+		writePrometheusLabelValue(&b, all[key])
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+type prometheusFamily struct {
+	typ     string
+	samples []string
+}
+
+// StatsPrometheus writes all of the name/value statistics pairs the Registry
+// knows to w in the Prometheus text exposition format. Metric names are made
+// from the series measurement and field, and series tags become labels.
+func StatsPrometheus(r *monkit.Registry, w io.Writer) (err error) {
+	// the exposition format requires all samples of a metric family to be
+	// grouped together, so buffer everything before writing.
+	families := map[string]*prometheusFamily{}
+	r.Stats(func(key monkit.SeriesKey, field string, val float64) {
+		name := PrometheusMetricName(key.Measurement, field)
+		family := families[name]
+		if family == nil {
+			family = &prometheusFamily{typ: PrometheusType(field)}
+			families[name] = family
+		}
+		family.samples = append(family.samples,
+			name+formatPrometheusLabels(field, key.Tags)+" "+formatPrometheusValue(val)+"\n")
+	})
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		family := families[name]
+		_, err = io.WriteString(w, "# TYPE "+name+" "+family.typ+"\n")
+		if err != nil {
+			return err
+		}
+		for _, sample := range family.samples {
+			_, err = io.WriteString(w, sample)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+func TestPrometheusNames(t *testing.T) {
+	for _, test := range []struct {
+		measurement, field, exp string
+	}{
+		{"function", "total", "function_total"},
+		{"hit 3", "rate", "hit_3_rate"},
+		{"3rd-party.calls", "count", "_3rd_party_calls_count"},
+		{"a:b", "value", "a:b_value"},
+	} {
+		got := PrometheusMetricName(test.measurement, test.field)
+		if got != test.exp {
+			t.Errorf("%q %q: got %q, expected %q", test.measurement, test.field, got, test.exp)
+		}
+	}
+	if got := PrometheusLabelName("a:b-c"); got != "a_b_c" {
+		t.Errorf("got %q", got)
+	}
+	got := PrometheusLabelNames([]string{"a.b", "a_b", "a-b", "a_b_2"})
+	if exp := []string{"a_b_4", "a_b", "a_b_3", "a_b_2"}; strings.Join(got, " ") != strings.Join(exp, " ") {
+		t.Errorf("got %q, expected %q", got, exp)
+	}
+	got = PrometheusLabelNames([]string{"__name__", "__x", "le", "exported_le"})
+	if exp := []string{"exported___name__", "exported___x", "exported_le_2", "exported_le"}; strings.Join(got, " ") != strings.Join(exp, " ") {
+		t.Errorf("got %q, expected %q", got, exp)
+	}
+}
+
+func TestStatsPrometheusReservedLabels(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	mon.Counter("beans", monkit.NewSeriesTag("__name__", "x"), monkit.NewSeriesTag("le", "y")).Inc(2)
+	f := mon.FuncNamed("work")
+	f.UseHistograms([]float64{1})
+	ctx := context.Background()
+	f.Task(&ctx)(nil)
+
+	var buf bytes.Buffer
+	if err := StatsPrometheus(r, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, exp := range []string{
+		`beans_value{exported___name__="x",exported_le="y",scope="test"} 2` + "\n",
+		`function_histogram_bucket{kind="success",le="1",name="work",scope="test"} 1` + "\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+}
+
+func TestStatsPrometheus(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	mon.Counter("beans", monkit.NewSeriesTag("kind", "a \"quoted\"\nvalue")).Inc(2)
+	mon.Meter("events").Mark(3)
+
+	var buf bytes.Buffer
+	if err := StatsPrometheus(r, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, exp := range []string{
+		"# TYPE beans_value gauge\n",
+		`beans_value{kind="a \"quoted\"\nvalue",scope="test"} 2` + "\n",
+		"# TYPE events_total counter\n",
+		`events_total{scope="test"} 3` + "\n",
+		"# TYPE beans_high gauge\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+	if strings.Count(out, "# TYPE events_rate ") != 1 {
+		t.Errorf("expected a single TYPE line per family, got:\n%s", out)
+	}
+}
+
+func TestStatsOpenMetrics(t *testing.T) {
+	r := monkit.NewRegistry()
+	mon := r.ScopeNamed("test")
+	mon.Meter("events").Mark(3)
+	f := mon.FuncNamed("work")
+	f.UseHistograms([]float64{1})
+	f.UseExemplars()
+	ctx := context.Background()
+	f.Task(&ctx)(nil)
+
+	var buf bytes.Buffer
+	if err := StatsOpenMetrics(r, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, exp := range []string{
+		"# TYPE events counter\n",
+		`events_total{scope="test"} 3` + "\n",
+		"# TYPE function_histogram histogram\n",
+		`function_histogram_bucket{kind="success",le="1",name="work",scope="test"} 1 # {trace_id="`,
+		`function_histogram_count{kind="success",name="work",scope="test"} 1` + "\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Errorf("expected output to end with # EOF, got:\n%s", out)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+)
+
+type recordedTraceGroup struct {
+	name   string
+	traces []*collect.RecordedTrace
+}
+
+func recordedTraceGroups(fr *collect.FlightRecorder) []recordedTraceGroup {
+	slowest := fr.Slowest()
+	funcs := make([]*monkit.Func, 0, len(slowest))
+	for f := range slowest {
+		funcs = append(funcs, f)
+	}
+	sort.Slice(funcs, func(i, j int) bool { return funcs[i].FullName() < funcs[j].FullName() })
+
+	groups := []recordedTraceGroup{
+		{name: "recent", traces: fr.Recent()},
+		{name: "errored", traces: fr.Errored()},
+		{name: "panicked", traces: fr.Panicked()},
+	}
+	for _, f := range funcs {
+		groups = append(groups, recordedTraceGroup{
+			name:   "slowest " + f.FullName(),
+			traces: []*collect.RecordedTrace{slowest[f]},
+		})
+	}
+	return groups
+}
+
+func recordedTraceName(rt *collect.RecordedTrace) string {
+	if rt.Root == nil {
+		return "(unknown)"
+	}
+	return rt.Root.Span.Func().FullName()
+}
+
+// RecordedTracesText writes a summary of the traces kept by fr to w in a
+// plain text format, grouped by why they were kept. Trace ids are in hex, as
+// expected by RecordedTraceSVG and RecordedTraceJSON.
+func RecordedTracesText(fr *collect.FlightRecorder, w io.Writer) (err error) {
+	for _, group := range recordedTraceGroups(fr) {
+		if len(group.traces) == 0 {
+			continue
+		}
+		_, err = fmt.Fprintf(w, "%s:\n", group.name)
+		if err != nil {
+			return err
+		}
+		for _, rt := range group.traces {
+			flags := ""
+			if rt.Panicked {
+				flags += ", panicked"
+			}
+			if rt.Errored {
+				flags += ", errored"
+			}
+			_, err = fmt.Fprintf(w, "  %x %s (duration: %s, spans: %d, start: %s%s)\n",
+				uint64(rt.TraceId), recordedTraceName(rt), rt.Duration(), len(rt.Spans),
+				rt.Start.Format("2006-01-02T15:04:05.000Z07:00"), flags)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RecordedTracesJSON writes a summary of the traces kept by fr to w in a
+// JSON format, grouped by why they were kept.
+func RecordedTracesJSON(fr *collect.FlightRecorder, w io.Writer) error {
+	lw := newListWriter(w)
+	for _, group := range recordedTraceGroups(fr) {
+		for _, rt := range group.traces {
+			lw.elem(struct {
+				Group    string `json:"group"`
+				TraceId  int64  `json:"trace_id"`
+				Name     string `json:"name"`
+				Start    int64  `json:"start"`
+				Finish   int64  `json:"finish"`
+				Spans    int    `json:"spans"`
+				Errored  bool   `json:"errored"`
+				Panicked bool   `json:"panicked"`
+			}{
+				Group:    group.name,
+				TraceId:  rt.TraceId,
+				Name:     recordedTraceName(rt),
+				Start:    rt.Start.UnixNano(),
+				Finish:   rt.Finish.UnixNano(),
+				Spans:    len(rt.Spans),
+				Errored:  rt.Errored,
+				Panicked: rt.Panicked,
+			})
+		}
+	}
+	return lw.done()
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/collect"
+)
+
+func TestRecentTraces(t *testing.T) {
+	r := monkit.NewRegistry()
+	if _, _, err := FromRequest(r, "/traces/recent", nil); err == nil {
+		t.Fatal("expected an error without a flight recorder")
+	}
+	fr, stop := collect.StartFlightRecorder(r, collect.FlightRecorderOptions{Recent: 1})
+	defer stop()
+
+	mon := r.ScopeNamed("test")
+	run := func(name string, fail bool) {
+		ctx := context.Background()
+		var err error
+		if fail {
+			err = errors.New("failed")
+		}
+		defer mon.FuncNamed(name).Task(&ctx)(&err)
+		defer mon.FuncNamed("child").Task(&ctx)(nil)
+	}
+	run("failing", true)
+	run("working", false)
+
+	if recent := fr.Recent(); len(recent) != 1 || len(recent[0].Spans) != 2 ||
+		recent[0].Root.Span.Func().ShortName() != "working" {
+		t.Fatalf("unexpected recent traces: %+v", recent)
+	}
+	errored := fr.Errored()
+	if len(errored) != 1 || !errored[0].Errored {
+		t.Fatalf("unexpected errored traces: %+v", errored)
+	}
+
+	render := func(path string, query url.Values) string {
+		f, _, err := FromRequest(r, path, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := f(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	out := render("/traces/recent", nil)
+	for _, exp := range []string{"recent:\n", "errored:\n", "slowest test.child:\n", "test.failing"} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+	query := url.Values{"trace_id": {fmt.Sprintf("%x", uint64(errored[0].TraceId))}}
+	if svg := render("/traces/recent/svg", query); !strings.Contains(svg, "test.failing") {
+		t.Fatalf("unexpected svg:\n%s", svg)
+	}
+}
@@ -17,6 +17,7 @@ package present
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/spacemonkeygo/monkit/v3"
 )
@@ -45,3 +46,41 @@ func StatsJSON(r *monkit.Registry, w io.Writer) (err error) {
 	})
 	return lw.done()
 }
+
+// StatsCardinality writes a report of every measurement the Registry knows
+// that hit a cardinality limit to w in a text format, listing its tag keys by
+// number of distinct values.
+func StatsCardinality(r *monkit.Registry, w io.Writer) (err error) {
+	r.Scopes(func(s *monkit.Scope) {
+		for _, report := range s.CardinalityReports() {
+			if err != nil {
+				return
+			}
+			_, err = fmt.Fprintf(w, "%s %s series=%d limit=%d overflowed=%d\n",
+				report.Scope, report.Measurement, report.Series, report.Limit,
+				report.Overflowed)
+			for _, tag := range report.TagKeys {
+				if err != nil {
+					return
+				}
+				_, err = fmt.Fprintf(w, "  %s: %d values\n", tag.Key, tag.Values)
+			}
+		}
+	})
+	return err
+}
+
+// StatsExemplars writes all of the exemplars the Registry knows to w in a
+// text format, one per line after the name of the stat it illustrates. Trace
+// and span ids are in hex.
+func StatsExemplars(r *monkit.Registry, w io.Writer) (err error) {
+	r.Exemplars(func(key monkit.SeriesKey, field string, ex monkit.Exemplar) {
+		if err != nil {
+			return
+		}
+		_, err = fmt.Fprintf(w, "%s=%f trace_id=%x span_id=%x time=%s\n",
+			key.WithField(field), ex.Value, uint64(ex.TraceId), uint64(ex.SpanId),
+			ex.Time.Format(time.RFC3339Nano))
+	})
+	return err
+}
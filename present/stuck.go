@@ -0,0 +1,80 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package present
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spacemonkeygo/monkit/v3"
+)
+
+// StuckText runs a check of the Registry's Watchdog and writes the stuck
+// Spans it finds to w in a plain text format, longest running first, with the
+// stacks of their goroutines.
+func StuckText(r *monkit.Registry, w io.Writer) (err error) {
+	stuck := r.Watchdog().Check()
+	if len(stuck) == 0 {
+		_, err = fmt.Fprintln(w, "no stuck spans")
+		return err
+	}
+	for _, st := range stuck {
+		s := st.Span
+		_, err = fmt.Fprintf(w, "[%d,%d] %s(%s) (elapsed: %s, threshold: %s)\n",
+			s.Id(), s.Trace().Id(), s.Func().FullName(), strings.Join(s.Args(), ", "),
+			st.Duration, st.Threshold)
+		if err != nil {
+			return err
+		}
+		for _, annotation := range s.Annotations() {
+			_, err = fmt.Fprintf(w, "  %s: %s\n", annotation.Name, annotation.Value)
+			if err != nil {
+				return err
+			}
+		}
+		stack := st.Stack
+		if stack == "" {
+			stack = "(goroutine stack unavailable)"
+		}
+		_, err = fmt.Fprintf(w, "  %s\n\n", strings.ReplaceAll(stack, "\n", "\n  "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StuckJSON runs a check of the Registry's Watchdog and writes the stuck
+// Spans it finds to w in a JSON format, longest running first.
+func StuckJSON(r *monkit.Registry, w io.Writer) error {
+	lw := newListWriter(w)
+	for _, st := range r.Watchdog().Check() {
+		lw.elem(struct {
+			Span      interface{} `json:"span"`
+			Duration  int64       `json:"duration"`
+			Threshold int64       `json:"threshold"`
+			Goroutine int64       `json:"goroutine,omitempty"`
+			Stack     string      `json:"stack,omitempty"`
+		}{
+			Span:      formatSpan(st.Span),
+			Duration:  int64(st.Duration),
+			Threshold: int64(st.Threshold),
+			Goroutine: st.Goroutine,
+			Stack:     st.Stack,
+		})
+	}
+	return lw.done()
+}
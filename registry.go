@@ -25,7 +25,11 @@ type traceWatcherRef struct {
 
 type registryInternal struct {
 	// sync/atomic things
-	traceWatcher *traceWatcherRef
+	traceWatcher    *traceWatcherRef
+	sampler         *samplerRef
+	pprofLabels     int32
+	trackGoroutines int32
+	trackLeaks      int32
 
 	watcherMtx     sync.Mutex
 	watcherCounter int64
@@ -39,6 +43,12 @@ type registryInternal struct {
 
 	orphanMtx sync.Mutex
 	orphans   map[*Span]struct{}
+
+	watchdogOnce sync.Once
+	watchdog     *Watchdog
+
+	leaksOnce sync.Once
+	leaks     *LeakDetector
 }
 
 // Registry encapsulates all of the top-level state for a monitoring system.
@@ -51,6 +61,7 @@ type Registry struct {
 	*registryInternal
 
 	transformers []CallbackTransformer
+	commonTags   *TagSet
 }
 
 // NewRegistry creates a NewRegistry, though you almost certainly just want
@@ -70,9 +81,29 @@ func (r *Registry) WithTransformers(t ...CallbackTransformer) *Registry {
 	return &Registry{
 		registryInternal: r.registryInternal,
 		transformers:     append(append([]CallbackTransformer(nil), r.transformers...), t...),
+		commonTags:       r.commonTags,
 	}
 }
 
+// WithCommonTags returns a copy of Registry with the given tags, like host,
+// service or version, added to every SeriesKey its Stats method reports.
+// Tags already on a SeriesKey take precedence. Like WithTransformers, the
+// returned Registry shares everything else, and calls can be chained; the
+// tags are applied by an InjectTags transformer added to the end of the
+// chain.
+//
+// The tags are also attached to every Trace passed to callbacks registered
+// with ObserveTraces on the returned Registry, where trace observers and
+// exporters can report them as resource attributes. See Trace.ResourceTags.
+func (r *Registry) WithCommonTags(tags ...SeriesTag) *Registry {
+	rv := r.WithTransformers(InjectTags(tags...))
+	rv.commonTags = r.commonTags.SetTags(tags...)
+	return rv
+}
+
+// CommonTags returns the tags added with WithCommonTags, or nil.
+func (r *Registry) CommonTags() *TagSet { return r.commonTags }
+
 // Package creates a new monitoring Scope, named after the top level package.
 // It's expected that you'll have something like
 //
@@ -96,9 +127,9 @@ func (r *Registry) ScopeNamed(name string) *Scope {
 	return s
 }
 
-func (r *Registry) observeTrace(t *Trace) {
+func (r *Registry) observeTrace(t *Trace, f *Func) {
 	watcher := loadTraceWatcherRef(&r.traceWatcher)
-	if watcher != nil {
+	if watcher != nil && r.sample(t, f) {
 		watcher.watcher(t)
 	}
 }
@@ -126,13 +157,22 @@ func (r *Registry) updateWatcher() {
 
 // ObserveTraces lets you observe all traces flowing through the system.
 // The passed in callback 'cb' will be called for every new trace as soon as
-// it starts, until the returned cancel method is called.
+// it starts, until the returned cancel method is called. If a Sampler has
+// been set with SetSampler, only sampled traces are passed to 'cb'.
 // Note: this only applies to all new traces. If you want to find existing
 // or running traces, please pull them off of live RootSpans.
 func (r *Registry) ObserveTraces(cb func(*Trace)) (cancel func()) {
 	// even though observeTrace doesn't get a mutex, it's only ever loading
 	// the traceWatcher pointer, so we can use this mutex here to safely
 	// coordinate the setting of the traceWatcher pointer.
+	if tags := r.commonTags; tags.Len() > 0 {
+		observe := cb
+		cb = func(t *Trace) {
+			t.addResourceTags(tags)
+			observe(t)
+		}
+	}
+
 	r.watcherMtx.Lock()
 	defer r.watcherMtx.Unlock()
 
@@ -0,0 +1,50 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithCommonTags(t *testing.T) {
+	base := NewRegistry()
+	r := base.WithCommonTags(NewSeriesTag("host", "a"), NewSeriesTag("scope", "ignored")).
+		WithTransformers(NewDeltaTransformer())
+	mon := r.ScopeNamed("tagged")
+	mon.Counter("conns").Inc(1)
+
+	r.Stats(func(key SeriesKey, field string, val float64) {
+		if key.Tags.Get("host") != "a" || key.Tags.Get("scope") != "tagged" {
+			t.Fatalf("unexpected tags on %v", key)
+		}
+	})
+	base.Stats(func(key SeriesKey, field string, val float64) {
+		if key.Tags.Get("host") != "" {
+			t.Fatalf("common tags leaked into base registry: %v", key)
+		}
+	})
+	if r.CommonTags().Get("host") != "a" {
+		t.Fatal("expected common tags to survive WithTransformers")
+	}
+
+	var resource *TagSet
+	defer r.ObserveTraces(func(tr *Trace) { resource = tr.ResourceTags() })()
+	ctx := context.Background()
+	mon.Task()(&ctx)(nil)
+	if resource.Get("host") != "a" {
+		t.Fatalf("expected resource tags on trace, got %v", resource)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"math"
+	"sync"
+
+	"github.com/spacemonkeygo/monkit/v3/monotime"
+)
+
+// Sampler decides which new traces are passed to the callbacks registered
+// with Registry.ObserveTraces. Sample is called once per trace, when its
+// root span is created, with the Func of that root span.
+type Sampler interface {
+	Sample(t *Trace, f *Func) bool
+}
+
+// SamplerFunc is a function that implements Sampler.
+type SamplerFunc func(t *Trace, f *Func) bool
+
+// Sample implements Sampler.
+func (s SamplerFunc) Sample(t *Trace, f *Func) bool { return s(t, f) }
+
+type samplerRef struct {
+	sampler Sampler
+}
+
+// SetSampler installs a Sampler on the Registry, which is shared with every
+// Registry returned by WithTransformers. Traces the Sampler rejects are never
+// handed to ObserveTraces callbacks, so trace observers, exporters and trace
+// queries from the present package only see sampled traces. A nil Sampler,
+// the default, samples every trace.
+func (r *Registry) SetSampler(s Sampler) {
+	var ref *samplerRef
+	if s != nil {
+		ref = &samplerRef{sampler: s}
+	}
+	storeSamplerRef(&r.sampler, ref)
+}
+
+func (r *Registry) sample(t *Trace, f *Func) bool {
+	ref := loadSamplerRef(&r.sampler)
+	return ref == nil || ref.sampler.Sample(t, f)
+}
+
+// AlwaysSample returns a Sampler that samples every trace.
+func AlwaysSample() Sampler {
+	return SamplerFunc(func(*Trace, *Func) bool { return true })
+}
+
+// NeverSample returns a Sampler that samples no traces.
+func NeverSample() Sampler {
+	return SamplerFunc(func(*Trace, *Func) bool { return false })
+}
+
+// ProbabilitySampler returns a Sampler that samples the given fraction of
+// traces, where 0 <= probability <= 1. The decision is derived from the
+// trace id, so processes sharing a remote trace agree on it.
+func ProbabilitySampler(probability float64) Sampler {
+	switch {
+	case probability <= 0:
+		return NeverSample()
+	case probability >= 1:
+		return AlwaysSample()
+	}
+	// trace ids are non-negative 63 bit numbers.
+	threshold := uint64(probability * math.MaxInt64)
+	return SamplerFunc(func(t *Trace, f *Func) bool {
+		return uint64(t.Id())&math.MaxInt64 < threshold
+	})
+}
+
+// RateLimitSampler returns a Sampler that samples at most perSecond traces
+// per second, allowing bursts of up to one second's worth (or one trace, if
+// perSecond is less than one).
+func RateLimitSampler(perSecond float64) Sampler {
+	if perSecond <= 0 {
+		return NeverSample()
+	}
+	burst := perSecond
+	if burst < 1 {
+		burst = 1
+	}
+	var mtx sync.Mutex
+	tokens := burst
+	last := monotime.Now()
+	return SamplerFunc(func(t *Trace, f *Func) bool {
+		now := monotime.Now()
+		mtx.Lock()
+		defer mtx.Unlock()
+		tokens += now.Sub(last).Seconds() * perSecond
+		last = now
+		if tokens > burst {
+			tokens = burst
+		}
+		if tokens < 1 {
+			return false
+		}
+		tokens--
+		return true
+	})
+}
+
+// SamplerRule pairs a Func matcher with the Sampler to use for traces rooted
+// at matching Funcs.
+type SamplerRule struct {
+	Match   func(f *Func) bool
+	Sampler Sampler
+}
+
+// RuleSampler returns a Sampler that consults the first rule matching the
+// Func of the root span, or fallback if no rule matches. Since the Func of a
+// root span doesn't change, the chosen rule is cached per Func.
+func RuleSampler(fallback Sampler, rules ...SamplerRule) Sampler {
+	if fallback == nil {
+		fallback = AlwaysSample()
+	}
+	var cache sync.Map // *Func -> Sampler
+	return SamplerFunc(func(t *Trace, f *Func) bool {
+		if s, ok := cache.Load(f); ok {
+			return s.(Sampler).Sample(t, f)
+		}
+		s := fallback
+		for _, rule := range rules {
+			if rule.Match(f) {
+				s = rule.Sampler
+				break
+			}
+		}
+		cache.Store(f, s)
+		return s.Sample(t, f)
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	r := NewRegistry()
+	mon := r.ScopeNamed("sampler")
+	sampled, unsampled := mon.FuncNamed("sampled"), mon.FuncNamed("unsampled")
+
+	r.SetSampler(RuleSampler(NeverSample(), SamplerRule{
+		Match:   func(f *Func) bool { return f == sampled },
+		Sampler: AlwaysSample(),
+	}))
+
+	observed := map[*Func]int{}
+	defer r.ObserveTraces(func(t *Trace) {
+		t.ObserveSpans(&funcCounter{counts: observed})
+	})()
+
+	for i := 0; i < 3; i++ {
+		ctx := context.Background()
+		func() {
+			defer sampled.Task(&ctx)(nil)
+			defer unsampled.Task(&ctx)(nil)
+		}()
+		ctx = context.Background()
+		unsampled.Task(&ctx)(nil)
+	}
+
+	// the unsampled Func is only observed as a child of a sampled trace.
+	if observed[sampled] != 3 || observed[unsampled] != 3 {
+		t.Fatalf("unexpected observations: %v", observed)
+	}
+
+	r.SetSampler(nil)
+	ctx := context.Background()
+	unsampled.Task(&ctx)(nil)
+	if observed[unsampled] != 4 {
+		t.Fatalf("expected every trace to be sampled without a sampler")
+	}
+}
+
+func TestProbabilitySampler(t *testing.T) {
+	s := ProbabilitySampler(0.25)
+	hits := 0
+	for i := 0; i < 10000; i++ {
+		if s.Sample(NewTrace(NewId()), nil) {
+			hits++
+		}
+	}
+	if hits < 2000 || hits > 3000 {
+		t.Fatalf("expected roughly 2500 samples, got %d", hits)
+	}
+}
+
+type funcCounter struct {
+	counts map[*Func]int
+}
+
+func (c *funcCounter) Start(s *Span) {}
+
+func (c *funcCounter) Finish(s *Span, err error, panicked bool, finish time.Time) {
+	c.counts[s.Func()]++
+}
@@ -18,23 +18,46 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3/monotime"
 )
 
 // Scope represents a named collection of StatSources. Scopes are constructed
 // through Registries.
 type Scope struct {
+	// sync/atomic things
+	idleExpiry int64
+	edges      edgeLimits
+
 	r       *Registry
 	name    string
 	mtx     sync.RWMutex
 	sources map[string]StatSource
+	meta    map[string]*sourceMeta
+	swept   time.Time
 	chains  []StatSource
+	limits  scopeLimits
+}
+
+// sourceMeta is the bookkeeping for a StatSource created by newSource.
+type sourceMeta struct {
+	// sync/atomic things
+	used int64 // last lookup, in monotime nanoseconds
+
+	// constructor things
+	measurement string
+	tags        []SeriesTag
+	overflow    bool
 }
 
 func newScope(r *Registry, name string) *Scope {
 	return &Scope{
 		r:       r,
 		name:    name,
-		sources: map[string]StatSource{}}
+		sources: map[string]StatSource{},
+		meta:    map[string]*sourceMeta{}}
 }
 
 // Func retrieves or creates a Func named after the currently executing
@@ -43,30 +66,84 @@ func (s *Scope) Func() *Func {
 	return s.FuncNamed(callerFunc(0))
 }
 
-func (s *Scope) newSource(name string, constructor func() StatSource) (
-	rv StatSource) {
+func (s *Scope) newSource(namespace, name string, tags []SeriesTag,
+	constructor func(tags []SeriesTag) StatSource) (rv StatSource) {
 
+	sname := sourceName(namespace, name, tags)
 	s.mtx.RLock()
-	source, exists := s.sources[name]
+	source, exists := s.sources[sname]
+	meta := s.meta[sname]
+	if !exists && len(tags) > 0 {
+		// lookups past the limit share the overflow series once it exists, so
+		// they don't need the write lock.
+		source, meta, exists = s.overflowSourceLocked(namespace, name, tags)
+	}
 	s.mtx.RUnlock()
 
 	if exists {
+		if meta != nil && atomic.LoadInt64(&s.idleExpiry) > 0 {
+			atomic.StoreInt64(&meta.used, monotime.Now().UnixNano())
+		}
 		return source
 	}
 
 	s.mtx.Lock()
-	if source, exists := s.sources[name]; exists {
-		s.mtx.Unlock()
+	defer s.mtx.Unlock()
+	if source, exists := s.sources[sname]; exists {
 		return source
 	}
 
-	ss := constructor()
-	s.sources[name] = ss
-	s.mtx.Unlock()
+	now := monotime.Now()
+	if idle := time.Duration(atomic.LoadInt64(&s.idleExpiry)); idle > 0 &&
+		now.Sub(s.swept) >= idle {
+		s.expireLocked(now, idle)
+	}
 
+	meta = &sourceMeta{
+		used:        now.UnixNano(),
+		measurement: namespace + name,
+		tags:        tags,
+	}
+	if len(tags) > 0 && s.overLimitLocked(meta.measurement) {
+		s.limits.overflow(meta.measurement)
+		meta.tags, meta.overflow = overflowTags(tags), true
+		sname = sourceName(namespace, name, meta.tags)
+		if source, exists := s.sources[sname]; exists {
+			atomic.StoreInt64(&s.meta[sname].used, meta.used)
+			return source
+		}
+	}
+
+	ss := constructor(meta.tags)
+	if f, ok := ss.(*Func); ok {
+		f.meta = meta
+	}
+	s.sources[sname] = ss
+	s.meta[sname] = meta
+	s.limits.added(meta)
 	return ss
 }
 
+// overflowSourceLocked returns the existing overflow series for a lookup
+// that is over its cardinality limit. It only needs the read lock, so it
+// leaves measurements without an overflow counter yet to the write-locked
+// path.
+func (s *Scope) overflowSourceLocked(namespace, name string,
+	tags []SeriesTag) (source StatSource, meta *sourceMeta, exists bool) {
+	measurement := namespace + name
+	counter, counted := s.limits.overflowed[measurement]
+	if !counted || !s.overLimitLocked(measurement) {
+		return nil, nil, false
+	}
+	sname := sourceName(namespace, name, overflowTags(tags))
+	source, exists = s.sources[sname]
+	if exists {
+		meta = s.meta[sname]
+		atomic.AddInt64(counter, 1)
+	}
+	return source, meta, exists
+}
+
 func sourceName(namespace, name string, tags []SeriesTag) string {
 	var sourceNameSize int
 	sourceNameSize += len(namespace) + len(name) + len(tags)*2
@@ -93,7 +170,7 @@ func sourceName(namespace, name string, tags []SeriesTag) string {
 // unique Func. SeriesTags are not sorted, so keep the order consistent to avoid
 // unintentionally creating new unique Funcs.
 func (s *Scope) FuncNamed(name string, tags ...SeriesTag) *Func {
-	source := s.newSource(sourceName("func:", name, tags), func() StatSource {
+	source := s.newSource("func:", name, tags, func(tags []SeriesTag) StatSource {
 		return newFunc(s, NewSeriesKey("function").WithTag("name", name).WithTags(tags...))
 	})
 	f, ok := source.(*Func)
@@ -106,6 +183,7 @@ func (s *Scope) FuncNamed(name string, tags ...SeriesTag) *Func {
 
 // Funcs calls 'cb' for all Funcs registered on this Scope.
 func (s *Scope) Funcs(cb func(f *Func)) {
+	s.ExpireIdle()
 	s.mtx.Lock()
 	funcs := make(map[*Func]struct{}, len(s.sources))
 	for _, source := range s.sources {
@@ -121,7 +199,7 @@ func (s *Scope) Funcs(cb func(f *Func)) {
 
 // Meter retrieves or creates a Meter named after the given name. See Event.
 func (s *Scope) Meter(name string, tags ...SeriesTag) *Meter {
-	source := s.newSource(sourceName("", name, tags), func() StatSource {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
 		return NewMeter(NewSeriesKey(name).WithTags(tags...))
 	})
 	m, ok := source.(*Meter)
@@ -141,7 +219,7 @@ func (s *Scope) Event(name string, tags ...SeriesTag) {
 // DiffMeter retrieves or creates a DiffMeter after the given name and two
 // submeters.
 func (s *Scope) DiffMeter(name string, m1, m2 *Meter, tags ...SeriesTag) {
-	source := s.newSource(sourceName("", name, tags), func() StatSource {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
 		return NewDiffMeter(NewSeriesKey(name).WithTags(tags...), m1, m2)
 	})
 	if _, ok := source.(*DiffMeter); !ok {
@@ -152,7 +230,7 @@ func (s *Scope) DiffMeter(name string, m1, m2 *Meter, tags ...SeriesTag) {
 
 // IntVal retrieves or creates an IntVal after the given name.
 func (s *Scope) IntVal(name string, tags ...SeriesTag) *IntVal {
-	source := s.newSource(sourceName("", name, tags), func() StatSource {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
 		return NewIntVal(NewSeriesKey(name).WithTags(tags...))
 	})
 	m, ok := source.(*IntVal)
@@ -171,7 +249,7 @@ func (s *Scope) IntValf(template string, args ...interface{}) *IntVal {
 
 // FloatVal retrieves or creates a FloatVal after the given name.
 func (s *Scope) FloatVal(name string, tags ...SeriesTag) *FloatVal {
-	source := s.newSource(sourceName("", name, tags), func() StatSource {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
 		return NewFloatVal(NewSeriesKey(name).WithTags(tags...))
 	})
 	m, ok := source.(*FloatVal)
@@ -190,7 +268,7 @@ func (s *Scope) FloatValf(template string, args ...interface{}) *FloatVal {
 
 // BoolVal retrieves or creates a BoolVal after the given name.
 func (s *Scope) BoolVal(name string, tags ...SeriesTag) *BoolVal {
-	source := s.newSource(sourceName("", name, tags), func() StatSource {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
 		return NewBoolVal(NewSeriesKey(name).WithTags(tags...))
 	})
 	m, ok := source.(*BoolVal)
@@ -209,7 +287,7 @@ func (s *Scope) BoolValf(template string, args ...interface{}) *BoolVal {
 
 // StructVal retrieves or creates a StructVal after the given name.
 func (s *Scope) StructVal(name string, tags ...SeriesTag) *StructVal {
-	source := s.newSource(sourceName("", name, tags), func() StatSource {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
 		return NewStructVal(NewSeriesKey(name).WithTags(tags...))
 	})
 	m, ok := source.(*StructVal)
@@ -222,7 +300,7 @@ func (s *Scope) StructVal(name string, tags ...SeriesTag) *StructVal {
 
 // DurationVal retrieves or creates a DurationVal after the given name.
 func (s *Scope) DurationVal(name string, tags ...SeriesTag) *DurationVal {
-	source := s.newSource(sourceName("", name, tags), func() StatSource {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
 		return NewDurationVal(NewSeriesKey(name).WithTags(tags...))
 	})
 	m, ok := source.(*DurationVal)
@@ -235,7 +313,7 @@ func (s *Scope) DurationVal(name string, tags ...SeriesTag) *DurationVal {
 
 // Timer retrieves or creates a Timer after the given name.
 func (s *Scope) Timer(name string, tags ...SeriesTag) *Timer {
-	source := s.newSource(sourceName("", name, tags), func() StatSource {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
 		return NewTimer(NewSeriesKey(name).WithTags(tags...))
 	})
 	m, ok := source.(*Timer)
@@ -248,7 +326,7 @@ func (s *Scope) Timer(name string, tags ...SeriesTag) *Timer {
 
 // Counter retrieves or creates a Counter after the given name.
 func (s *Scope) Counter(name string, tags ...SeriesTag) *Counter {
-	source := s.newSource(sourceName("", name, tags), func() StatSource {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
 		return NewCounter(NewSeriesKey(name).WithTags(tags...))
 	})
 	m, ok := source.(*Counter)
@@ -259,11 +337,26 @@ func (s *Scope) Counter(name string, tags ...SeriesTag) *Counter {
 	return m
 }
 
+// Histogram retrieves or creates a Histogram after the given name. The
+// buckets are only used when the Histogram is first created.
+func (s *Scope) Histogram(name string, buckets []float64,
+	tags ...SeriesTag) *Histogram {
+	source := s.newSource("", name, tags, func(tags []SeriesTag) StatSource {
+		return NewHistogram(NewSeriesKey(name).WithTags(tags...), buckets)
+	})
+	m, ok := source.(*Histogram)
+	if !ok {
+		panic(fmt.Sprintf("%s already used for another stats source: %#v",
+			name, source))
+	}
+	return m
+}
+
+type gauge struct{ StatSource }
+
 // Gauge registers a callback that returns a float as the given name in the
 // Scope's StatSource table.
 func (s *Scope) Gauge(name string, cb func() float64) {
-	type gauge struct{ StatSource }
-
 	// gauges allow overwriting
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
@@ -292,6 +385,106 @@ func (s *Scope) Chain(source StatSource) {
 	s.chains = append(s.chains, source)
 }
 
+// Remove unregisters the StatSource that Meter, Event, DiffMeter, IntVal,
+// FloatVal, BoolVal, StructVal, DurationVal, Timer, Counter or Histogram
+// created for the given name and tags, or the Gauge with the given name.
+// The series no longer shows up in Stats. Existing references keep working,
+// but a later lookup with the same name and tags creates a new StatSource.
+// Remove reports whether there was a StatSource to remove.
+func (s *Scope) Remove(name string, tags ...SeriesTag) bool {
+	return s.remove(sourceName("", name, tags))
+}
+
+// RemoveFunc unregisters the Func that FuncNamed created for the given name
+// and tags, so it no longer shows up in Funcs, Stats or as the parent of
+// other Funcs. Spans of the Func that are still running finish normally.
+// RemoveFunc reports whether there was a Func to remove.
+func (s *Scope) RemoveFunc(name string, tags ...SeriesTag) bool {
+	return s.remove(sourceName("func:", name, tags))
+}
+
+func (s *Scope) remove(name string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.removeLocked(name)
+}
+
+func (s *Scope) removeLocked(name string) bool {
+	source, exists := s.sources[name]
+	if !exists {
+		return false
+	}
+	delete(s.sources, name)
+	if meta, ok := s.meta[name]; ok {
+		delete(s.meta, name)
+		s.limits.removed(meta)
+	}
+	switch source := source.(type) {
+	case *Meter:
+		defaultTicker.unregister(source)
+	case *Func:
+		atomic.StoreInt32(&source.removed, 1)
+		source.unlink()
+		s.r.Watchdog().forget(source)
+		s.r.LeakDetector().forget(source)
+	}
+	return true
+}
+
+// SetIdleExpiry sets up the Scope to remove StatSources and Funcs that
+// haven't been looked up by name, through methods like IntVal or FuncNamed,
+// for at least the given duration. This bounds the memory used by series
+// with many distinct tag values, like per-peer or per-tenant tags, and keeps
+// stale series out of Stats. Funcs with running spans are never removed.
+//
+// Since idleness is tracked by lookups, references to StatSources that are
+// kept and used directly look idle. Only enable expiry on Scopes whose
+// series are looked up on every use. Funcs are also marked used whenever one
+// of their spans starts, so Funcs kept by mon.Task() stay registered. Gauges
+// and Chained sources never expire. Expiry is checked when the Scope's Stats
+// or Funcs are collected and when new series are created. A zero duration,
+// the default, disables expiry.
+func (s *Scope) SetIdleExpiry(idle time.Duration) {
+	now := monotime.Now().UnixNano()
+	s.mtx.Lock()
+	for _, meta := range s.meta {
+		atomic.StoreInt64(&meta.used, now)
+	}
+	atomic.StoreInt64(&s.idleExpiry, int64(idle))
+	s.mtx.Unlock()
+}
+
+// ExpireIdle removes series that have been idle longer than the duration
+// configured with SetIdleExpiry, and returns how many were removed. It is
+// called automatically, but may be called to free memory sooner.
+func (s *Scope) ExpireIdle() (removed int) {
+	idle := time.Duration(atomic.LoadInt64(&s.idleExpiry))
+	if idle <= 0 {
+		return 0
+	}
+	s.mtx.Lock()
+	removed = s.expireLocked(monotime.Now(), idle)
+	s.mtx.Unlock()
+	return removed
+}
+
+func (s *Scope) expireLocked(now time.Time, idle time.Duration) (removed int) {
+	s.swept = now
+	cutoff := now.Add(-idle).UnixNano()
+	for name, meta := range s.meta {
+		if atomic.LoadInt64(&meta.used) > cutoff {
+			continue
+		}
+		if f, ok := s.sources[name].(*Func); ok && f.Current() > 0 {
+			continue
+		}
+		if s.removeLocked(name) {
+			removed++
+		}
+	}
+	return removed
+}
+
 func (s *Scope) allNamedSources() (sources []namedSource) {
 	s.mtx.Lock()
 	sources = make([]namedSource, 0, len(s.sources))
@@ -304,11 +497,23 @@ func (s *Scope) allNamedSources() (sources []namedSource) {
 
 // Stats implements the StatSource interface.
 func (s *Scope) Stats(cb func(key SeriesKey, field string, val float64)) {
+	s.stats(cb, nil)
+}
+
+// stats is Stats, calling before, if not nil, with the kind of each source
+// before the source reports its stats.
+func (s *Scope) stats(cb func(key SeriesKey, field string, val float64),
+	before func(kind SourceKind)) {
+	if before == nil {
+		before = func(SourceKind) {}
+	}
+	s.ExpireIdle()
 	cbWithScope := func(key SeriesKey, field string, val float64) {
 		cb(key.WithTag("scope", s.name), field, val)
 	}
 
 	for _, namedSource := range s.allNamedSources() {
+		before(sourceKind(namedSource.source))
 		namedSource.source.Stats(cbWithScope)
 	}
 
@@ -316,9 +521,20 @@ func (s *Scope) Stats(cb func(key SeriesKey, field string, val float64)) {
 	chains := append([]StatSource(nil), s.chains...)
 	s.mtx.Unlock()
 
+	before(KindOther)
 	for _, source := range chains {
 		source.Stats(cbWithScope)
 	}
+
+	for _, report := range s.CardinalityReports() {
+		key := NewSeriesKey("cardinality_limit").WithTag("measurement", report.Measurement)
+		cbWithScope(key, "series", float64(report.Series))
+		cbWithScope(key, "limit", float64(report.Limit))
+		cbWithScope(key, "overflowed", float64(report.Overflowed))
+	}
+
+	s.r.Watchdog().stats(s, cbWithScope)
+	s.r.LeakDetector().stats(s, cbWithScope)
 }
 
 // Name returns the name of the Scope, often the Package name.
@@ -0,0 +1,189 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func countSeries(s *Scope, measurement string) (n int) {
+	s.Stats(func(key SeriesKey, field string, val float64) {
+		if key.Measurement == measurement {
+			n++
+		}
+	})
+	return n
+}
+
+func TestScopeRemove(t *testing.T) {
+	mon := NewRegistry().ScopeNamed("remove")
+	peer := NewSeriesTag("peer", "a")
+	mon.Counter("conns", peer).Inc(1)
+	mon.Meter("bytes", peer).Mark(10)
+	grandparent := mon.FuncNamed("grandparent")
+	parent, child := mon.FuncNamed("parent"), mon.FuncNamed("child")
+	parent.start(grandparent)
+	child.start(parent)
+
+	if !mon.Remove("conns", peer) || mon.Remove("conns", peer) {
+		t.Fatal("expected exactly one successful removal")
+	}
+	if countSeries(mon, "conns") != 0 {
+		t.Fatal("removed counter still in stats")
+	}
+	if !mon.Remove("bytes", peer) || !mon.RemoveFunc("parent") {
+		t.Fatal("expected removals to succeed")
+	}
+	child.Parents(func(f *Func) {
+		t.Fatalf("removed parent still reported: %v", f)
+	})
+	child.parents(func(f *Func) {
+		t.Fatalf("removed parent still referenced: %v", f)
+	})
+	if len(grandparent.children) != 0 {
+		t.Fatal("removed child still referenced")
+	}
+	if mon.Counter("conns", peer).Current() != 0 {
+		t.Fatal("expected a new counter after removal")
+	}
+}
+
+func TestScopeIdleExpiry(t *testing.T) {
+	mon := NewRegistry().ScopeNamed("expiry")
+	mon.IntVal("size", NewSeriesTag("tenant", "a")).Observe(1)
+	running := mon.FuncNamed("running")
+	running.start(nil)
+	cached := mon.FuncNamed("cached")
+
+	mon.SetIdleExpiry(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	// used without a lookup, like a Func kept by mon.Task().
+	cached.start(nil)
+	cached.end(nil, false, 0, nil)
+	mon.IntVal("size", NewSeriesTag("tenant", "b")).Observe(1)
+
+	if removed := mon.ExpireIdle(); removed != 0 {
+		t.Fatalf("expected expiry already swept on creation, removed %d", removed)
+	}
+	found := 0
+	mon.Stats(func(key SeriesKey, field string, val float64) {
+		if key.Measurement == "size" && field == "count" {
+			found++
+			if key.Tags.Get("tenant") != "b" {
+				t.Fatalf("unexpected series %v", key)
+			}
+		}
+	})
+	if found != 1 {
+		t.Fatalf("expected only the recent series, found %d", found)
+	}
+	funcs := 0
+	mon.Funcs(func(f *Func) { funcs++ })
+	if funcs != 2 {
+		t.Fatal("expected running and recently used funcs to survive expiry")
+	}
+}
+
+func TestScopeCardinalityLimits(t *testing.T) {
+	mon := NewRegistry().ScopeNamed("limits")
+	mon.SetCardinalityLimits(CardinalityLimits{Measurement: 2})
+
+	for _, user := range []string{"a", "b", "c", "d"} {
+		mon.Counter("requests", NewSeriesTag("user", user), NewSeriesTag("op", "get")).Inc(1)
+	}
+	mon.Counter("untagged").Inc(1)
+
+	overflow := mon.Counter("requests", NewSeriesTag("user", "e"), NewSeriesTag("op", "get"))
+	if overflow.Current() != 2 {
+		t.Fatalf("expected the overflow series to be shared, got %d", overflow.Current())
+	}
+
+	reports := mon.CardinalityReports()
+	if len(reports) != 1 {
+		t.Fatalf("expected one report, got %+v", reports)
+	}
+	r := reports[0]
+	if r.Measurement != "requests" || r.Series != 2 || r.Limit != 2 || r.Overflowed != 3 ||
+		r.TagKeys[0] != (TagCardinality{Key: "user", Values: 2}) {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	if countSeries(mon, "cardinality_limit") != 3 {
+		t.Fatal("expected cardinality self-metric")
+	}
+
+	// removing a series frees room under the limit.
+	mon.Remove("requests", NewSeriesTag("user", "a"), NewSeriesTag("op", "get"))
+	if mon.Counter("requests", NewSeriesTag("user", "f"), NewSeriesTag("op", "get")).Current() != 0 {
+		t.Fatal("expected a new series after removal")
+	}
+}
+
+func TestScopeCardinalityOverflowConcurrent(t *testing.T) {
+	mon := NewRegistry().ScopeNamed("limits")
+	mon.SetCardinalityLimits(CardinalityLimits{Measurement: 1})
+	mon.Counter("requests", NewSeriesTag("user", "a")).Inc(1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				user := NewSeriesTag("user", fmt.Sprint("user", i, "-", j%10))
+				mon.Counter("requests", user).Inc(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	overflow := mon.Counter("requests", NewSeriesTag("user", "z"))
+	if overflow.Current() != 800 {
+		t.Fatalf("expected all over-limit lookups to share one series, got %d", overflow.Current())
+	}
+	reports := mon.CardinalityReports()
+	if len(reports) != 1 || reports[0].Overflowed != 801 || reports[0].Series != 1 {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+}
+
+func TestScopeCardinalityOverflowPrecreated(t *testing.T) {
+	mon := NewRegistry().ScopeNamed("limits")
+	// the overflow series exists before any lookup overflowed.
+	overflow := mon.Counter("requests", NewSeriesTag("user", OverflowTagValue))
+	mon.SetCardinalityLimits(CardinalityLimits{Measurement: 1})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				mon.Counter("requests", NewSeriesTag("user", fmt.Sprint("user", i, "-", j))).Inc(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if overflow.Current() != 800 {
+		t.Fatalf("expected all over-limit lookups to share one series, got %d", overflow.Current())
+	}
+	reports := mon.CardinalityReports()
+	if len(reports) != 1 || reports[0].Overflowed != 800 {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+const (
+	sketchMaxBins         = 2048
+	sketchEncodingV1      = 1
+	defaultSketchAccuracy = 0.01
+)
+
+// Sketch is a quantile sketch in the style of DDSketch. Values are counted in
+// logarithmically sized buckets, so quantile queries have a bounded relative
+// error, and sketches with the same relative accuracy can be merged exactly,
+// across time windows or processes. Sketches are not threadsafe.
+//
+// Each sign keeps at most 2048 buckets. If values span more than that, the
+// buckets closest to zero are collapsed together and lose their accuracy
+// guarantee; with the default 1% accuracy, 2048 buckets cover about 17 orders
+// of magnitude.
+type Sketch struct {
+	alpha    float64
+	logGamma float64
+
+	positive sketchStore
+	negative sketchStore
+	zero     int64
+	count    int64
+	sum      float64
+	min      float64
+	max      float64
+}
+
+// NewSketch creates a Sketch whose quantiles are within relativeAccuracy of
+// the true value, where 0 < relativeAccuracy < 1. Out of range accuracies
+// default to 0.01.
+func NewSketch(relativeAccuracy float64) *Sketch {
+	s := &Sketch{}
+	initSketch(s, relativeAccuracy)
+	return s
+}
+
+func initSketch(s *Sketch, relativeAccuracy float64) {
+	if !(relativeAccuracy > 0 && relativeAccuracy < 1) {
+		relativeAccuracy = defaultSketchAccuracy
+	}
+	s.alpha = relativeAccuracy
+	s.logGamma = math.Log((1 + relativeAccuracy) / (1 - relativeAccuracy))
+	s.min, s.max = math.Inf(1), math.Inf(-1)
+}
+
+// RelativeAccuracy returns the relative accuracy the Sketch was created with.
+func (s *Sketch) RelativeAccuracy() float64 { return s.alpha }
+
+// Add adds a value to the sketch. NaN and infinite values are ignored.
+func (s *Sketch) Add(val float64) {
+	switch {
+	case math.IsNaN(val) || math.IsInf(val, 0):
+		return
+	case val > 0:
+		s.positive.add(s.index(val), 1)
+	case val < 0:
+		s.negative.add(s.index(-val), 1)
+	default:
+		s.zero++
+	}
+	s.count++
+	s.sum += val
+	if val < s.min {
+		s.min = val
+	}
+	if val > s.max {
+		s.max = val
+	}
+}
+
+func (s *Sketch) index(val float64) int {
+	return int(math.Ceil(math.Log(val) / s.logGamma))
+}
+
+func (s *Sketch) value(index int) float64 {
+	// the midpoint, relative to the bucket bounds, of bucket index.
+	return math.Exp(float64(index)*s.logGamma) * (1 - s.alpha)
+}
+
+// Count returns the number of values added.
+func (s *Sketch) Count() int64 { return s.count }
+
+// Sum returns the sum of all values added.
+func (s *Sketch) Sum() float64 { return s.sum }
+
+// Min returns the smallest value added, or NaN if the sketch is empty.
+func (s *Sketch) Min() float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	return s.min
+}
+
+// Max returns the largest value added, or NaN if the sketch is empty.
+func (s *Sketch) Max() float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	return s.max
+}
+
+// Quantile returns an estimate of the requested quantile of added values,
+// where 0 <= quantile <= 1, or NaN if the sketch is empty.
+func (s *Sketch) Quantile(quantile float64) float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	if quantile <= 0 {
+		return s.min
+	}
+	if quantile >= 1 {
+		return s.max
+	}
+	rank := int64(quantile * float64(s.count-1))
+
+	var rv float64
+	seen := int64(0)
+	switch {
+	case rank < s.negative.total():
+		// negative values are ordered from the largest magnitude down.
+		for i := len(s.negative.counts) - 1; i >= 0; i-- {
+			seen += s.negative.counts[i]
+			if seen > rank {
+				rv = -s.value(s.negative.offset + i)
+				break
+			}
+		}
+	case rank < s.negative.total()+s.zero:
+		rv = 0
+	default:
+		seen = s.negative.total() + s.zero
+		for i, c := range s.positive.counts {
+			seen += c
+			if seen > rank {
+				rv = s.value(s.positive.offset + i)
+				break
+			}
+		}
+	}
+	return math.Max(s.min, math.Min(s.max, rv))
+}
+
+// Merge adds the values from other into s. Both sketches must have the same
+// relative accuracy.
+func (s *Sketch) Merge(other *Sketch) error {
+	if other.alpha != s.alpha {
+		return fmt.Errorf("monkit: can't merge sketches with relative accuracy %v and %v",
+			s.alpha, other.alpha)
+	}
+	if other.count == 0 {
+		return nil
+	}
+	s.positive.merge(&other.positive)
+	s.negative.merge(&other.negative)
+	s.zero += other.zero
+	s.count += other.count
+	s.sum += other.sum
+	s.min = math.Min(s.min, other.min)
+	s.max = math.Max(s.max, other.max)
+	return nil
+}
+
+// Copy returns a full copy of the sketch.
+func (s *Sketch) Copy() *Sketch {
+	c := *s
+	c.positive.counts = append([]int64(nil), s.positive.counts...)
+	c.negative.counts = append([]int64(nil), s.negative.counts...)
+	return &c
+}
+
+// Reset removes all values from the sketch.
+func (s *Sketch) Reset() {
+	initSketch(s, s.alpha)
+	s.positive, s.negative = sketchStore{}, sketchStore{}
+	s.zero, s.count, s.sum = 0, 0, 0
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The encoding is stable,
+// so sketches can be sent between processes and merged there.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	buf := []byte{sketchEncodingV1}
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.alpha))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.sum))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.min))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.max))
+	buf = binary.AppendUvarint(buf, uint64(s.zero))
+	buf = s.positive.appendBinary(buf)
+	buf = s.negative.appendBinary(buf)
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the
+// contents of s with a sketch encoded by MarshalBinary.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < 33 || data[0] != sketchEncodingV1 {
+		return fmt.Errorf("monkit: invalid sketch encoding")
+	}
+	alpha := math.Float64frombits(binary.LittleEndian.Uint64(data[1:]))
+	if !(alpha > 0 && alpha < 1) {
+		return fmt.Errorf("monkit: invalid sketch relative accuracy %v", alpha)
+	}
+	var rv Sketch
+	initSketch(&rv, alpha)
+	rv.sum = math.Float64frombits(binary.LittleEndian.Uint64(data[9:]))
+	rv.min = math.Float64frombits(binary.LittleEndian.Uint64(data[17:]))
+	rv.max = math.Float64frombits(binary.LittleEndian.Uint64(data[25:]))
+	data = data[33:]
+
+	zero, n := binary.Uvarint(data)
+	if n <= 0 || int64(zero) < 0 {
+		return fmt.Errorf("monkit: invalid sketch encoding")
+	}
+	rv.zero = int64(zero)
+	data = data[n:]
+	// bound the bucket indexes to those of finite values, so that a corrupt
+	// sketch can't make later merges allocate huge stores.
+	low, high := rv.index(math.SmallestNonzeroFloat64), rv.index(math.MaxFloat64)
+	var err error
+	if data, err = rv.positive.readBinary(data, low, high); err != nil {
+		return err
+	}
+	if _, err = rv.negative.readBinary(data, low, high); err != nil {
+		return err
+	}
+	// every count fits in an int64, but their total may not.
+	rv.count = rv.zero
+	for _, store := range []*sketchStore{&rv.positive, &rv.negative} {
+		for _, c := range store.counts {
+			if rv.count > math.MaxInt64-c {
+				return fmt.Errorf("monkit: invalid sketch encoding")
+			}
+			rv.count += c
+		}
+	}
+	*s = rv
+	return nil
+}
+
+// sketchStore counts values by bucket index in a dense slice starting at
+// offset, collapsing the lowest buckets to stay within sketchMaxBins.
+type sketchStore struct {
+	offset int
+	counts []int64
+}
+
+func (s *sketchStore) add(index int, n int64) {
+	if len(s.counts) == 0 {
+		s.offset, s.counts = index, []int64{n}
+		return
+	}
+	high := s.offset + len(s.counts) - 1
+	if index > high {
+		s.counts = append(s.counts, make([]int64, index-high)...)
+		high = index
+	}
+	if low := high - sketchMaxBins + 1; index < low {
+		index = low
+	}
+	if index < s.offset {
+		s.counts = append(make([]int64, s.offset-index), s.counts...)
+		s.offset = index
+	}
+	if extra := len(s.counts) - sketchMaxBins; extra > 0 {
+		var collapsed int64
+		for _, c := range s.counts[:extra+1] {
+			collapsed += c
+		}
+		s.counts = append([]int64(nil), s.counts[extra:]...)
+		s.counts[0] = collapsed
+		s.offset += extra
+	}
+	s.counts[index-s.offset] += n
+}
+
+func (s *sketchStore) merge(other *sketchStore) {
+	for i, c := range other.counts {
+		if c != 0 {
+			s.add(other.offset+i, c)
+		}
+	}
+}
+
+func (s *sketchStore) total() (rv int64) {
+	for _, c := range s.counts {
+		rv += c
+	}
+	return rv
+}
+
+func (s *sketchStore) appendBinary(buf []byte) []byte {
+	buf = binary.AppendVarint(buf, int64(s.offset))
+	buf = binary.AppendUvarint(buf, uint64(len(s.counts)))
+	for _, c := range s.counts {
+		buf = binary.AppendUvarint(buf, uint64(c))
+	}
+	return buf
+}
+
+// readBinary reads a store written by appendBinary, whose bucket indexes must
+// all be between low and high.
+func (s *sketchStore) readBinary(data []byte, low, high int) ([]byte, error) {
+	offset, n := binary.Varint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("monkit: invalid sketch encoding")
+	}
+	data = data[n:]
+	length, n := binary.Uvarint(data)
+	if n <= 0 || length > sketchMaxBins {
+		return nil, fmt.Errorf("monkit: invalid sketch encoding")
+	}
+	if length > 0 && (offset < int64(low) || offset+int64(length)-1 > int64(high)) {
+		return nil, fmt.Errorf("monkit: invalid sketch bucket offset %d", offset)
+	}
+	data = data[n:]
+	s.offset = int(offset)
+	s.counts = nil
+	if length > 0 {
+		s.counts = make([]int64, length)
+	}
+	for i := range s.counts {
+		c, n := binary.Uvarint(data)
+		if n <= 0 || int64(c) < 0 {
+			return nil, fmt.Errorf("monkit: invalid sketch encoding")
+		}
+		s.counts[i] = int64(c)
+		data = data[n:]
+	}
+	return data, nil
+}
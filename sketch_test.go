@@ -0,0 +1,119 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSketch(t *testing.T) {
+	a, b := NewSketch(0.01), NewSketch(0.01)
+	for i := 1; i <= 1000; i++ {
+		a.Add(float64(i))
+		b.Add(float64(-i))
+	}
+
+	check := func(s *Sketch, quantile, expected float64) {
+		t.Helper()
+		if got := s.Quantile(quantile); math.Abs(got-expected) > 0.01*math.Abs(expected)+1e-9 {
+			t.Fatalf("quantile %v: expected about %v, got %v", quantile, expected, got)
+		}
+	}
+	check(a, 0.5, 500)
+	check(a, 0.99, 990)
+	check(b, 0.01, -990)
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	if a.Count() != 2000 || a.Sum() != 0 || a.Min() != -1000 || a.Max() != 1000 {
+		t.Fatalf("unexpected merged sketch: count %d sum %v min %v max %v",
+			a.Count(), a.Sum(), a.Min(), a.Max())
+	}
+	check(a, 0.75, 500)
+	if err := a.Merge(NewSketch(0.05)); err == nil {
+		t.Fatal("expected merging different accuracies to fail")
+	}
+
+	data, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Sketch
+	if err := c.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if c.Count() != a.Count() || c.Quantile(0.3) != a.Quantile(0.3) {
+		t.Fatal("sketch changed after encoding round trip")
+	}
+}
+
+func TestFloatValSketch(t *testing.T) {
+	v := NewFloatVal(NewSeriesKey("v"))
+	if v.Sketch() != nil {
+		t.Fatal("expected no sketch by default")
+	}
+	v.UseSketch(0.02)
+	for i := 0; i < 100; i++ {
+		v.Observe(float64(i))
+	}
+	if s := v.Sketch(); s.Count() != 100 || s.RelativeAccuracy() != 0.02 {
+		t.Fatalf("unexpected sketch: count %d", s.Count())
+	}
+}
+
+func TestSketchUnmarshalInvalid(t *testing.T) {
+	s := NewSketch(0.01)
+	s.Add(math.MaxFloat64)
+	s.Add(math.SmallestNonzeroFloat64)
+	s.Add(-math.MaxFloat64)
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Sketch
+	if err := c.UnmarshalBinary(data); err != nil {
+		t.Fatalf("expected extreme values to round trip: %v", err)
+	}
+
+	s = NewSketch(0.01)
+	s.Add(1)
+	s.positive.offset = 1 << 40
+	if data, _ = s.MarshalBinary(); c.UnmarshalBinary(data) == nil {
+		t.Fatal("expected an out of range bucket offset to be rejected")
+	}
+	s.positive.offset = -1 << 40
+	if data, _ = s.MarshalBinary(); c.UnmarshalBinary(data) == nil {
+		t.Fatal("expected an out of range bucket offset to be rejected")
+	}
+	s.positive.offset, s.alpha = 0, 2
+	if data, _ = s.MarshalBinary(); c.UnmarshalBinary(data) == nil {
+		t.Fatal("expected an invalid relative accuracy to be rejected")
+	}
+	s.alpha = 0.01
+	s.zero = -1
+	if data, _ = s.MarshalBinary(); c.UnmarshalBinary(data) == nil {
+		t.Fatal("expected a negative zero count to be rejected")
+	}
+	s.zero = math.MaxInt64
+	if data, _ = s.MarshalBinary(); c.UnmarshalBinary(data) == nil {
+		t.Fatal("expected an overflowing total count to be rejected")
+	}
+	s.zero = math.MaxInt64 - 1
+	if data, _ = s.MarshalBinary(); c.UnmarshalBinary(data) != nil || c.count != math.MaxInt64 {
+		t.Fatal("expected the largest total count to round trip")
+	}
+}
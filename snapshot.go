@@ -0,0 +1,191 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"sort"
+	"time"
+)
+
+// SourceKind is the kind of StatSource a Series came from.
+type SourceKind string
+
+// The kinds of StatSources a Scope creates. KindOther covers Chained
+// StatSources and anything else.
+const (
+	KindFunc      SourceKind = "func"
+	KindMeter     SourceKind = "meter"
+	KindCounter   SourceKind = "counter"
+	KindDist      SourceKind = "dist"
+	KindHistogram SourceKind = "histogram"
+	KindBool      SourceKind = "bool"
+	KindStruct    SourceKind = "struct"
+	KindGauge     SourceKind = "gauge"
+	KindOther     SourceKind = "other"
+)
+
+func sourceKind(source StatSource) SourceKind {
+	switch source.(type) {
+	case *Func:
+		return KindFunc
+	case *Meter, *DiffMeter:
+		return KindMeter
+	case *Counter:
+		return KindCounter
+	case *IntVal, *FloatVal, *DurationVal, *Timer:
+		return KindDist
+	case *Histogram:
+		return KindHistogram
+	case *BoolVal:
+		return KindBool
+	case *StructVal:
+		return KindStruct
+	case gauge:
+		return KindGauge
+	}
+	return KindOther
+}
+
+// Series is a single series of a Snapshot: every field reported for a
+// SeriesKey, and the kind of StatSource that reported it.
+type Series struct {
+	Key    SeriesKey
+	Kind   SourceKind
+	Fields map[string]float64
+}
+
+// Snapshot is a point in time copy of everything a Registry's Stats reports,
+// with series sorted by key. Snapshots may be shared, so they must not be
+// modified.
+type Snapshot struct {
+	Time   time.Time
+	Series []Series
+
+	index map[string]int
+}
+
+// Snapshot collects the Registry's Stats, with its transformers applied, into
+// a Snapshot.
+func (r *Registry) Snapshot() *Snapshot {
+	snap := &Snapshot{Time: time.Now(), index: map[string]int{}}
+	kind := KindOther
+	var cb func(key SeriesKey, field string, val float64) = func(
+		key SeriesKey, field string, val float64) {
+		name := key.String()
+		i, ok := snap.index[name]
+		if !ok {
+			i = len(snap.Series)
+			snap.index[name] = i
+			snap.Series = append(snap.Series, Series{
+				Key: key, Kind: kind, Fields: map[string]float64{}})
+		}
+		snap.Series[i].Fields[field] = val
+	}
+	for _, t := range r.transformers {
+		cb = t.Transform(cb)
+	}
+	r.Scopes(func(s *Scope) {
+		s.stats(cb, func(k SourceKind) { kind = k })
+	})
+
+	sort.Slice(snap.Series, func(i, j int) bool {
+		return snap.Series[i].Key.String() < snap.Series[j].Key.String()
+	})
+	for i, series := range snap.Series {
+		snap.index[series.Key.String()] = i
+	}
+	return snap
+}
+
+// Lookup returns the Series with the given key, if any.
+func (s *Snapshot) Lookup(key SeriesKey) (Series, bool) {
+	i, ok := s.index[key.String()]
+	if !ok {
+		return Series{}, false
+	}
+	return s.Series[i], true
+}
+
+// Stats implements the StatSource interface, so a Snapshot can be handed to
+// anything that reports a StatSource. Fields are reported in sorted order.
+func (s *Snapshot) Stats(cb func(key SeriesKey, field string, val float64)) {
+	for _, series := range s.Series {
+		fields := make([]string, 0, len(series.Fields))
+		for field := range series.Fields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			cb(series.Key, field, series.Fields[field])
+		}
+	}
+}
+
+// FieldDiff is the change of a field between two Snapshots. Rate is the
+// Delta per second.
+type FieldDiff struct {
+	Delta float64
+	Rate  float64
+}
+
+// SeriesDiff is the change of a Series between two Snapshots.
+type SeriesDiff struct {
+	Key    SeriesKey
+	Kind   SourceKind
+	Fields map[string]FieldDiff
+}
+
+// SnapshotDiff is the change between two Snapshots, with series sorted by
+// key.
+type SnapshotDiff struct {
+	Time    time.Time
+	Elapsed time.Duration
+	Series  []SeriesDiff
+}
+
+// Diff returns the change of every field since prev. Series and fields that
+// aren't in both Snapshots are left out. Deltas are computed for every field,
+// though they are most meaningful for fields that only grow, like total or
+// count. A counter that was reset shows up as a negative delta.
+func (s *Snapshot) Diff(prev *Snapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{Time: s.Time, Elapsed: s.Time.Sub(prev.Time)}
+	seconds := diff.Elapsed.Seconds()
+	for _, series := range s.Series {
+		old, ok := prev.Lookup(series.Key)
+		if !ok {
+			continue
+		}
+		sd := SeriesDiff{
+			Key:    series.Key,
+			Kind:   series.Kind,
+			Fields: make(map[string]FieldDiff, len(series.Fields)),
+		}
+		for field, val := range series.Fields {
+			oldVal, ok := old.Fields[field]
+			if !ok {
+				continue
+			}
+			fd := FieldDiff{Delta: val - oldVal}
+			if seconds > 0 {
+				fd.Rate = fd.Delta / seconds
+			}
+			sd.Fields[field] = fd
+		}
+		diff.Series = append(diff.Series, sd)
+	}
+	return diff
+}
+
+var _ StatSource = (*Snapshot)(nil)
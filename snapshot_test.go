@@ -0,0 +1,55 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	r := NewRegistry()
+	mon := r.ScopeNamed("snap")
+	counter := mon.Counter("conns")
+	counter.Inc(3)
+	mon.IntVal("size").Observe(10)
+	mon.Gauge("temp", func() float64 { return 42 })
+
+	prev := r.Snapshot()
+	series, ok := prev.Lookup(NewSeriesKey("conns").WithTag("scope", "snap"))
+	if !ok || series.Kind != KindCounter || series.Fields["value"] != 3 {
+		t.Fatalf("unexpected counter series: %+v", series)
+	}
+	if series, _ := prev.Lookup(NewSeriesKey("size").WithTag("scope", "snap")); series.Kind != KindDist {
+		t.Fatalf("unexpected dist series: %+v", series)
+	}
+	if series, _ := prev.Lookup(NewSeriesKey("temp").WithTag("scope", "snap")); series.Kind != KindGauge {
+		t.Fatalf("unexpected gauge series: %+v", series)
+	}
+
+	counter.Inc(2)
+	cur := r.Snapshot()
+	cur.Time = prev.Time.Add(2 * time.Second)
+	diff := cur.Diff(prev)
+	for _, sd := range diff.Series {
+		if sd.Key.Measurement == "conns" {
+			if fd := sd.Fields["value"]; fd.Delta != 2 || fd.Rate != 1 {
+				t.Fatalf("unexpected diff: %+v", fd)
+			}
+			return
+		}
+	}
+	t.Fatal("counter missing from diff")
+}
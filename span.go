@@ -51,6 +51,33 @@ func (s *Span) removeChild(child *Span) {
 	s.mtx.Unlock()
 }
 
+// unregister marks the Span done, orphaning its children, and removes it
+// from its parent or the Registry.
+func (s *Span) unregister() {
+	var children []*Span
+	s.mtx.Lock()
+	s.done = true
+	orphaned := s.orphaned
+	s.children.Iterate(func(child *Span) {
+		children = append(children, child)
+	})
+	s.mtx.Unlock()
+	for _, child := range children {
+		child.orphan()
+	}
+
+	if s.parent != nil {
+		s.parent.removeChild(s)
+		if orphaned {
+			s.f.scope.r.orphanEnd(s)
+		}
+	} else {
+		s.f.scope.r.rootSpanEnd(s)
+	}
+
+	s.trace.decrementSpans()
+}
+
 func (s *Span) orphan() {
 	s.mtx.Lock()
 	if !s.done && !s.orphaned {
@@ -44,8 +44,9 @@ type Trace struct {
 	id int64
 
 	// protected by mtx
-	mtx  sync.Mutex
-	vals map[interface{}]interface{}
+	mtx      sync.Mutex
+	vals     map[interface{}]interface{}
+	resource *TagSet
 }
 
 // NewTrace creates a new Trace.
@@ -108,6 +109,25 @@ func removeObserverFrom(parent **spanObserverTuple, ref *spanObserverTuple) (
 // Id returns the id of the Trace
 func (t *Trace) Id() int64 { return t.id }
 
+// ResourceTags returns the common tags of the Registries observing the
+// Trace, which describe the process rather than any one span. See
+// Registry.WithCommonTags.
+func (t *Trace) ResourceTags() *TagSet {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.resource
+}
+
+func (t *Trace) addResourceTags(tags *TagSet) {
+	t.mtx.Lock()
+	if t.resource == nil || t.resource == tags {
+		t.resource = tags
+	} else {
+		t.resource = t.resource.SetAll(tags.All())
+	}
+	t.mtx.Unlock()
+}
+
 // GetAll returns values associated with a trace. See SetAll.
 func (t *Trace) GetAll() (val map[interface{}]interface{}) {
 	t.mtx.Lock()
@@ -0,0 +1,249 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Filter is a CallbackTransformer that drops stats by measurement and field
+// name. A stat passes if it matches every non-nil Allow expression and no
+// non-nil Deny expression. Expected usage like:
+//
+//	reg := monkit.Default.WithTransformers(monkit.Filter{
+//	  DenyMeasurements: regexp.MustCompile(`^(debug|internal)_`),
+//	  AllowFields:      regexp.MustCompile(`^(total|count|r50|r99)$`),
+//	})
+type Filter struct {
+	AllowMeasurements *regexp.Regexp
+	DenyMeasurements  *regexp.Regexp
+	AllowFields       *regexp.Regexp
+	DenyFields        *regexp.Regexp
+}
+
+// Transform implements CallbackTransformer.
+func (f Filter) Transform(cb func(SeriesKey, string, float64)) func(SeriesKey, string, float64) {
+	return func(key SeriesKey, field string, val float64) {
+		if (f.AllowMeasurements != nil && !f.AllowMeasurements.MatchString(key.Measurement)) ||
+			(f.DenyMeasurements != nil && f.DenyMeasurements.MatchString(key.Measurement)) ||
+			(f.AllowFields != nil && !f.AllowFields.MatchString(field)) ||
+			(f.DenyFields != nil && f.DenyFields.MatchString(field)) {
+			return
+		}
+		cb(key, field, val)
+	}
+}
+
+// DropTags returns a CallbackTransformer that removes the given tag keys from
+// every SeriesKey. Series that only differed by a dropped tag are reported
+// separately under the same key, so this is mostly useful for tags that
+// don't add cardinality, or in front of an aggregating output.
+func DropTags(keys ...string) CallbackTransformer {
+	drop := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		drop[key] = true
+	}
+	return CallbackTransformerFunc(func(
+		cb func(SeriesKey, string, float64)) func(SeriesKey, string, float64) {
+		return func(key SeriesKey, field string, val float64) {
+			key.Tags = filterTags(key.Tags, func(k, v string) (string, bool) {
+				return k, !drop[k]
+			})
+			cb(key, field, val)
+		}
+	})
+}
+
+// RenameTags returns a CallbackTransformer that renames tag keys, mapping
+// old names to new ones. If a renamed key is already used by a tag that is
+// not renamed, the existing tag wins and the renamed one is dropped.
+func RenameTags(renames map[string]string) CallbackTransformer {
+	return CallbackTransformerFunc(func(
+		cb func(SeriesKey, string, float64)) func(SeriesKey, string, float64) {
+		return func(key SeriesKey, field string, val float64) {
+			key.Tags = filterTags(key.Tags, func(k, v string) (string, bool) {
+				if renamed, ok := renames[k]; ok {
+					return renamed, true
+				}
+				return k, true
+			})
+			cb(key, field, val)
+		}
+	})
+}
+
+// filterTags returns the tag set with every key mapped through fn, dropping
+// the tags fn rejects. If fn changes nothing, tags is returned as is. When a
+// renamed key collides with another tag, a tag that kept its key wins, and
+// otherwise the tag with the smallest original key wins.
+func filterTags(tags *TagSet, fn func(key, val string) (string, bool)) *TagSet {
+	changed := false
+	for k, v := range tags.All() {
+		if newKey, keep := fn(k, v); !keep || newKey != k {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return tags
+	}
+	all := make(map[string]string, tags.Len())
+	var renamed []string
+	for k, v := range tags.All() {
+		if newKey, keep := fn(k, v); keep {
+			if newKey != k {
+				renamed = append(renamed, k)
+				continue
+			}
+			all[k] = v
+		}
+	}
+	sort.Strings(renamed)
+	for _, k := range renamed {
+		v := tags.Get(k)
+		newKey, _ := fn(k, v)
+		if _, exists := all[newKey]; !exists {
+			all[newKey] = v
+		}
+	}
+	return &TagSet{all: all}
+}
+
+// InjectTags returns a CallbackTransformer that adds the given tags to every
+// SeriesKey. Tags already on a SeriesKey take precedence.
+func InjectTags(tags ...SeriesTag) CallbackTransformer {
+	var injected *TagSet
+	injected = injected.SetTags(tags...)
+	return CallbackTransformerFunc(func(
+		cb func(SeriesKey, string, float64)) func(SeriesKey, string, float64) {
+		return func(key SeriesKey, field string, val float64) {
+			key.Tags = injected.SetAll(key.Tags.All())
+			cb(key, field, val)
+		}
+	})
+}
+
+// RenameMeasurements returns a CallbackTransformer that renames
+// measurements, mapping old names to new ones. It helps keep dashboards
+// working while code migrates to new names.
+func RenameMeasurements(renames map[string]string) CallbackTransformer {
+	return CallbackTransformerFunc(func(
+		cb func(SeriesKey, string, float64)) func(SeriesKey, string, float64) {
+		return func(key SeriesKey, field string, val float64) {
+			if renamed, ok := renames[key.Measurement]; ok {
+				key.Measurement = renamed
+			}
+			cb(key, field, val)
+		}
+	})
+}
+
+// Scale is a CallbackTransformer that multiplies the values of matching
+// stats by Factor, to convert units. Nil expressions match everything. For
+// instance, to report an IntVal of nanoseconds in seconds:
+//
+//	monkit.Scale{
+//	  Measurements: regexp.MustCompile(`_ns$`),
+//	  Fields:       regexp.MustCompile(`^(sum|min|max|recent|ravg|rmin|rmax|r\d+)$`),
+//	  Factor:       1e-9,
+//	}
+//
+// Take care not to scale fields like count, which don't share the unit of
+// the observed values.
+type Scale struct {
+	Measurements *regexp.Regexp
+	Fields       *regexp.Regexp
+	Factor       float64
+}
+
+// Transform implements CallbackTransformer.
+func (s Scale) Transform(cb func(SeriesKey, string, float64)) func(SeriesKey, string, float64) {
+	return func(key SeriesKey, field string, val float64) {
+		if (s.Measurements == nil || s.Measurements.MatchString(key.Measurement)) &&
+			(s.Fields == nil || s.Fields.MatchString(field)) {
+			val *= s.Factor
+		}
+		cb(key, field, val)
+	}
+}
+
+// RateTransformer calculates per-second rates of monotonic fields, using the
+// time elapsed between collections. For each matching field, it reports an
+// additional field named after it with a "_rate" suffix, starting from the
+// second collection. Each Transform call, made once per Stats call by
+// Registry.Stats and TransformStatSource, counts as a collection. If a field
+// decreases, as when a counter is reset, no rate is reported for that
+// collection. Like DeltaTransformer, it keeps internal state, so use a
+// different RateTransformer per output.
+type RateTransformer struct {
+	fields map[string]bool
+	now    func() time.Time
+
+	mtx  sync.Mutex
+	last map[string]rateSample
+}
+
+type rateSample struct {
+	val float64
+	at  time.Time
+}
+
+// NewRateTransformer creates a RateTransformer for the given fields, or the
+// "total" field if none are given.
+func NewRateTransformer(fields ...string) *RateTransformer {
+	if len(fields) == 0 {
+		fields = []string{"total"}
+	}
+	rt := &RateTransformer{
+		fields: make(map[string]bool, len(fields)),
+		now:    time.Now,
+		last:   map[string]rateSample{},
+	}
+	for _, field := range fields {
+		rt.fields[field] = true
+	}
+	return rt
+}
+
+// Transform implements CallbackTransformer.
+func (rt *RateTransformer) Transform(cb func(SeriesKey, string, float64)) func(SeriesKey, string, float64) {
+	now := rt.now()
+	return func(key SeriesKey, field string, val float64) {
+		cb(key, field, val)
+		if !rt.fields[field] {
+			return
+		}
+
+		mapIndex := key.WithField(field)
+		rt.mtx.Lock()
+		last, found := rt.last[mapIndex]
+		rt.last[mapIndex] = rateSample{val: val, at: now}
+		rt.mtx.Unlock()
+
+		elapsed := now.Sub(last.at).Seconds()
+		if found && elapsed > 0 && val >= last.val {
+			cb(key, field+"_rate", (val-last.val)/elapsed)
+		}
+	}
+}
+
+var (
+	_ CallbackTransformer = Filter{}
+	_ CallbackTransformer = Scale{}
+	_ CallbackTransformer = (*RateTransformer)(nil)
+)
@@ -0,0 +1,88 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestTransformers(t *testing.T) {
+	source := StatSourceFunc(func(cb func(key SeriesKey, field string, val float64)) {
+		key := NewSeriesKey("latency_ns").WithTag("host", "a").WithTag("peer", "b")
+		cb(key, "sum", 2e9)
+		cb(key, "count", 4)
+		cb(NewSeriesKey("debug_thing"), "value", 1)
+	})
+
+	// the last transformer sees the stats first.
+	stats := Collect(TransformStatSource(source,
+		InjectTags(NewSeriesTag("region", "x"), NewSeriesTag("hostname", "ignored")),
+		RenameTags(map[string]string{"host": "hostname"}),
+		DropTags("peer"),
+		RenameMeasurements(map[string]string{"latency_ns": "latency"}),
+		Scale{Measurements: regexp.MustCompile(`_ns$`), Fields: regexp.MustCompile(`^sum$`), Factor: 1e-9},
+		Filter{DenyMeasurements: regexp.MustCompile(`^debug_`)},
+	))
+
+	expected := map[string]float64{
+		"latency,hostname=a,region=x sum":   2,
+		"latency,hostname=a,region=x count": 4,
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("unexpected stats: %v", stats)
+	}
+	for name, val := range expected {
+		if stats[name] != val {
+			t.Fatalf("%s: expected %v, got %v (all: %v)", name, val, stats[name], stats)
+		}
+	}
+}
+
+func TestRenameTagsCollision(t *testing.T) {
+	source := StatSourceFunc(func(cb func(key SeriesKey, field string, val float64)) {
+		cb(NewSeriesKey("m").WithTag("host", "a").WithTag("hostname", "b"), "value", 1)
+		cb(NewSeriesKey("n").WithTag("host", "a").WithTag("node", "c"), "value", 2)
+	})
+
+	for i := 0; i < 10; i++ {
+		stats := Collect(TransformStatSource(source, RenameTags(map[string]string{
+			"host": "hostname",
+			"node": "hostname",
+		})))
+		if stats["m,hostname=b value"] != 1 || stats["n,hostname=a value"] != 2 || len(stats) != 2 {
+			t.Fatalf("unexpected stats: %v", stats)
+		}
+	}
+}
+
+func TestRateTransformer(t *testing.T) {
+	total := 10.0
+	source := StatSourceFunc(func(cb func(key SeriesKey, field string, val float64)) {
+		cb(NewSeriesKey("m"), "total", total)
+	})
+	rt := NewRateTransformer()
+	now := time.Unix(100, 0)
+	rt.now = func() time.Time { return now }
+
+	if _, ok := Collect(TransformStatSource(source, rt))["m total_rate"]; ok {
+		t.Fatal("expected no rate on first collection")
+	}
+	total, now = 40, now.Add(10*time.Second)
+	if rate := Collect(TransformStatSource(source, rt))["m total_rate"]; rate != 3 {
+		t.Fatalf("expected rate 3, got %v", rate)
+	}
+}
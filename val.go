@@ -32,8 +32,10 @@ import (
 //   }
 //
 type IntVal struct {
-	mtx  sync.Mutex
-	dist IntDist
+	mtx    sync.Mutex
+	dist   IntDist
+	sketch *Sketch
+	window *WindowedDist
 }
 
 // NewIntVal creates an IntVal
@@ -47,6 +49,12 @@ func NewIntVal(key SeriesKey) (v *IntVal) {
 func (v *IntVal) Observe(val int64) {
 	v.mtx.Lock()
 	v.dist.Insert(val)
+	if v.sketch != nil {
+		v.sketch.Add(float64(val))
+	}
+	if v.window != nil {
+		v.window.Insert(float64(val))
+	}
 	v.mtx.Unlock()
 }
 
@@ -54,9 +62,16 @@ func (v *IntVal) Observe(val int64) {
 func (v *IntVal) Stats(cb func(key SeriesKey, field string, val float64)) {
 	v.mtx.Lock()
 	vd := v.dist.Copy()
+	var wd *WindowedDist
+	if v.window != nil {
+		wd = v.window.Copy()
+	}
 	v.mtx.Unlock()
 
 	vd.Stats(cb)
+	if wd != nil {
+		wd.Stats(cb)
+	}
 }
 
 // Quantile returns an estimate of the requested quantile of observed values.
@@ -68,6 +83,49 @@ func (v *IntVal) Quantile(quantile float64) (rv int64) {
 	return rv
 }
 
+// UseWindow makes the IntVal additionally keep a WindowedDist of values
+// observed within the last slots slot lengths of time, reported by Stats as
+// the wcount, wmin, wmax, wavg and w-quantile fields. Calling UseWindow
+// again starts a new, empty window.
+func (v *IntVal) UseWindow(slots int, slotLength time.Duration) {
+	v.mtx.Lock()
+	v.window = NewWindowedDist(v.dist.key, slots, slotLength)
+	v.mtx.Unlock()
+}
+
+// Window returns a Sketch of the values observed within the window, or nil
+// if UseWindow hasn't been called.
+func (v *IntVal) Window() *Sketch {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	if v.window == nil {
+		return nil
+	}
+	return v.window.Window()
+}
+
+// UseSketch makes the IntVal additionally keep a Sketch of all observed
+// values with the given relative accuracy. Unlike the sampled reservoir
+// behind Quantile, sketches can be merged across instances and time windows.
+// Calling UseSketch again starts a new, empty Sketch.
+func (v *IntVal) UseSketch(relativeAccuracy float64) {
+	sketch := NewSketch(relativeAccuracy)
+	v.mtx.Lock()
+	v.sketch = sketch
+	v.mtx.Unlock()
+}
+
+// Sketch returns a copy of the Sketch of observed values, or nil if UseSketch
+// hasn't been called.
+func (v *IntVal) Sketch() *Sketch {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	if v.sketch == nil {
+		return nil
+	}
+	return v.sketch.Copy()
+}
+
 // FloatVal is a convenience wrapper around an FloatDist. Constructed using
 // NewFloatVal, though its expected usage is like:
 //
@@ -80,8 +138,10 @@ func (v *IntVal) Quantile(quantile float64) (rv int64) {
 //   }
 //
 type FloatVal struct {
-	mtx  sync.Mutex
-	dist FloatDist
+	mtx    sync.Mutex
+	dist   FloatDist
+	sketch *Sketch
+	window *WindowedDist
 }
 
 // NewFloatVal creates a FloatVal
@@ -95,6 +155,12 @@ func NewFloatVal(key SeriesKey) (v *FloatVal) {
 func (v *FloatVal) Observe(val float64) {
 	v.mtx.Lock()
 	v.dist.Insert(val)
+	if v.sketch != nil {
+		v.sketch.Add(val)
+	}
+	if v.window != nil {
+		v.window.Insert(val)
+	}
 	v.mtx.Unlock()
 }
 
@@ -102,9 +168,16 @@ func (v *FloatVal) Observe(val float64) {
 func (v *FloatVal) Stats(cb func(key SeriesKey, field string, val float64)) {
 	v.mtx.Lock()
 	vd := v.dist.Copy()
+	var wd *WindowedDist
+	if v.window != nil {
+		wd = v.window.Copy()
+	}
 	v.mtx.Unlock()
 
 	vd.Stats(cb)
+	if wd != nil {
+		wd.Stats(cb)
+	}
 }
 
 // Quantile returns an estimate of the requested quantile of observed values.
@@ -116,6 +189,49 @@ func (v *FloatVal) Quantile(quantile float64) (rv float64) {
 	return rv
 }
 
+// UseWindow makes the FloatVal additionally keep a WindowedDist of values
+// observed within the last slots slot lengths of time, reported by Stats as
+// the wcount, wmin, wmax, wavg and w-quantile fields. Calling UseWindow
+// again starts a new, empty window.
+func (v *FloatVal) UseWindow(slots int, slotLength time.Duration) {
+	v.mtx.Lock()
+	v.window = NewWindowedDist(v.dist.key, slots, slotLength)
+	v.mtx.Unlock()
+}
+
+// Window returns a Sketch of the values observed within the window, or nil
+// if UseWindow hasn't been called.
+func (v *FloatVal) Window() *Sketch {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	if v.window == nil {
+		return nil
+	}
+	return v.window.Window()
+}
+
+// UseSketch makes the FloatVal additionally keep a Sketch of all observed
+// values with the given relative accuracy. Unlike the sampled reservoir
+// behind Quantile, sketches can be merged across instances and time windows.
+// Calling UseSketch again starts a new, empty Sketch.
+func (v *FloatVal) UseSketch(relativeAccuracy float64) {
+	sketch := NewSketch(relativeAccuracy)
+	v.mtx.Lock()
+	v.sketch = sketch
+	v.mtx.Unlock()
+}
+
+// Sketch returns a copy of the Sketch of observed values, or nil if UseSketch
+// hasn't been called.
+func (v *FloatVal) Sketch() *Sketch {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	if v.sketch == nil {
+		return nil
+	}
+	return v.sketch.Copy()
+}
+
 // BoolVal keeps statistics about boolean values. It keeps the number of trues,
 // number of falses, and the disposition (number of trues minus number of
 // falses). Constructed using NewBoolVal, though its expected usage is like:
@@ -216,8 +332,9 @@ func (v *StructVal) Stats(cb func(key SeriesKey, field string, val float64)) {
 //   }
 //
 type DurationVal struct {
-	mtx  sync.Mutex
-	dist DurationDist
+	mtx    sync.Mutex
+	dist   DurationDist
+	window *WindowedDist
 }
 
 // NewDurationVal creates an DurationVal
@@ -231,6 +348,9 @@ func NewDurationVal(key SeriesKey) (v *DurationVal) {
 func (v *DurationVal) Observe(val time.Duration) {
 	v.mtx.Lock()
 	v.dist.Insert(val)
+	if v.window != nil {
+		v.window.Insert(val.Seconds())
+	}
 	v.mtx.Unlock()
 }
 
@@ -238,9 +358,16 @@ func (v *DurationVal) Observe(val time.Duration) {
 func (v *DurationVal) Stats(cb func(key SeriesKey, field string, val float64)) {
 	v.mtx.Lock()
 	vd := v.dist.Copy()
+	var wd *WindowedDist
+	if v.window != nil {
+		wd = v.window.Copy()
+	}
 	v.mtx.Unlock()
 
 	vd.Stats(cb)
+	if wd != nil {
+		wd.Stats(cb)
+	}
 }
 
 // Quantile returns an estimate of the requested quantile of observed values.
@@ -251,3 +378,24 @@ func (v *DurationVal) Quantile(quantile float64) (rv time.Duration) {
 	v.mtx.Unlock()
 	return rv
 }
+
+// UseWindow makes the DurationVal additionally keep a WindowedDist of values
+// observed within the last slots slot lengths of time, reported by Stats as
+// the wcount, wmin, wmax, wavg and w-quantile fields. Durations are recorded
+// in seconds. Calling UseWindow again starts a new, empty window.
+func (v *DurationVal) UseWindow(slots int, slotLength time.Duration) {
+	v.mtx.Lock()
+	v.window = NewWindowedDist(v.dist.key, slots, slotLength)
+	v.mtx.Unlock()
+}
+
+// Window returns a Sketch of the values observed within the window, or nil
+// if UseWindow hasn't been called.
+func (v *DurationVal) Window() *Sketch {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+	if v.window == nil {
+		return nil
+	}
+	return v.window.Window()
+}
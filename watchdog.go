@@ -0,0 +1,277 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StuckSpan describes a Span a Watchdog found running longer than its
+// threshold.
+type StuckSpan struct {
+	Span      *Span
+	Duration  time.Duration
+	Threshold time.Duration
+
+	// Goroutine is the id of the goroutine that started the Span, and Stack
+	// its stack at the time of the check, if it was still running.
+	Goroutine int64
+	Stack     string
+}
+
+// Watchdog periodically scans the running Spans of a Registry for Spans that
+// have been running longer than a global or per-Func threshold. Every
+// Registry has one, returned by Registry.Watchdog, which does nothing until
+// a threshold is set and Run is called. Expected usage like:
+//
+//	wd := monkit.Default.Watchdog()
+//	wd.SetThreshold(time.Minute)
+//	wd.SetFuncThreshold(mon.FuncNamed("Poll"), time.Hour)
+//	go wd.Run(ctx, 10*time.Second)
+//
+// Funcs with stuck Spans are reported in Stats on the "function_stuck" series
+// with the "current" and "total" fields, the number of stuck Spans at the
+// last check and the number ever found. The present package lists the stuck
+// Spans at /stuck.
+type Watchdog struct {
+	r *Registry
+
+	mtx        sync.Mutex
+	threshold  time.Duration
+	thresholds map[*Func]time.Duration
+	stuck      []StuckSpan
+	seen       map[*Span]bool
+	current    map[*Func]int64
+	totals     map[*Func]int64
+}
+
+// Watchdog returns the Registry's Watchdog, which is shared with every
+// Registry returned by WithTransformers.
+func (r *Registry) Watchdog() *Watchdog {
+	r.watchdogOnce.Do(func() {
+		r.watchdog = &Watchdog{
+			r:          r,
+			thresholds: map[*Func]time.Duration{},
+			seen:       map[*Span]bool{},
+			current:    map[*Func]int64{},
+			totals:     map[*Func]int64{},
+		}
+	})
+	return r.watchdog
+}
+
+// SetThreshold sets the duration after which Spans of Funcs without their
+// own threshold are considered stuck. Zero, the default, disables the global
+// threshold.
+//
+// While any positive threshold is set, every new Span records the id of the
+// goroutine that started it, at a small cost, so the Watchdog can capture its
+// stack. Clearing all thresholds stops the recording.
+func (w *Watchdog) SetThreshold(d time.Duration) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.threshold = d
+	w.trackGoroutinesLocked()
+}
+
+// SetFuncThreshold sets the duration after which Spans of f are considered
+// stuck, overriding the global threshold. A negative duration exempts f,
+// while zero removes the override.
+func (w *Watchdog) SetFuncThreshold(f *Func, d time.Duration) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if d == 0 {
+		delete(w.thresholds, f)
+	} else {
+		w.thresholds[f] = d
+	}
+	w.trackGoroutinesLocked()
+}
+
+// trackGoroutinesLocked makes new Spans record their goroutine only while a
+// positive threshold is set.
+func (w *Watchdog) trackGoroutinesLocked() {
+	track := w.threshold > 0
+	for _, d := range w.thresholds {
+		track = track || d > 0
+	}
+	var val int32
+	if track {
+		val = 1
+	}
+	atomic.StoreInt32(&w.r.trackGoroutines, val)
+}
+
+// forget drops the threshold and stuck Span counts of a removed Func.
+func (w *Watchdog) forget(f *Func) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if _, ok := w.thresholds[f]; ok {
+		delete(w.thresholds, f)
+		w.trackGoroutinesLocked()
+	}
+	delete(w.current, f)
+	delete(w.totals, f)
+}
+
+// Run calls Check every interval until ctx is canceled.
+func (w *Watchdog) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		w.Check()
+	}
+}
+
+// Check scans the running Spans once, updating the stuck Span counts, and
+// returns the stuck Spans, longest running first.
+func (w *Watchdog) Check() []StuckSpan {
+	w.mtx.Lock()
+	threshold := w.threshold
+	thresholds := make(map[*Func]time.Duration, len(w.thresholds))
+	for f, d := range w.thresholds {
+		thresholds[f] = d
+	}
+	w.mtx.Unlock()
+
+	var stuck []StuckSpan
+	w.r.AllSpans(func(s *Span) {
+		limit, ok := thresholds[s.f]
+		if !ok {
+			limit = threshold
+		}
+		if limit <= 0 {
+			return
+		}
+		if d := s.Duration(); d > limit {
+			stuck = append(stuck, StuckSpan{
+				Span:      s,
+				Duration:  d,
+				Threshold: limit,
+				Goroutine: s.goroutine,
+			})
+		}
+	})
+	sort.Slice(stuck, func(i, j int) bool {
+		return stuck[i].Duration > stuck[j].Duration
+	})
+
+	if len(stuck) > 0 {
+		stacks := goroutineStacks()
+		for i := range stuck {
+			stuck[i].Stack = stacks[stuck[i].Goroutine]
+		}
+	}
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	seen := make(map[*Span]bool, len(stuck))
+	current := make(map[*Func]int64, len(w.current))
+	for _, st := range stuck {
+		if !w.seen[st.Span] {
+			w.totals[st.Span.f]++
+		}
+		seen[st.Span] = true
+		current[st.Span.f]++
+	}
+	w.seen, w.current, w.stuck = seen, current, stuck
+	return append([]StuckSpan(nil), stuck...)
+}
+
+// Stuck returns the stuck Spans found by the last check, longest running
+// first.
+func (w *Watchdog) Stuck() []StuckSpan {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]StuckSpan(nil), w.stuck...)
+}
+
+// stats reports the stuck Span counts of the Funcs in the Scope.
+func (w *Watchdog) stats(s *Scope, cb func(key SeriesKey, field string, val float64)) {
+	type funcCounts struct {
+		f              *Func
+		current, total int64
+	}
+	var counts []funcCounts
+	w.mtx.Lock()
+	for f, total := range w.totals {
+		if f.scope == s {
+			counts = append(counts, funcCounts{f: f, current: w.current[f], total: total})
+		}
+	}
+	w.mtx.Unlock()
+	sort.Slice(counts, func(i, j int) bool { return counts[i].f.id < counts[j].f.id })
+
+	for _, c := range counts {
+		key := c.f.key
+		key.Measurement += "_stuck"
+		cb(key, "current", float64(c.current))
+		cb(key, "total", float64(c.total))
+	}
+}
+
+var goroutinePrefix = []byte("goroutine ")
+
+// currentGoroutine returns the id of the calling goroutine, parsed from the
+// header of its stack trace.
+func currentGoroutine() int64 {
+	var buf [64]byte
+	id, _ := parseGoroutineHeader(buf[:runtime.Stack(buf[:], false)])
+	return id
+}
+
+func parseGoroutineHeader(stack []byte) (id int64, ok bool) {
+	if !bytes.HasPrefix(stack, goroutinePrefix) {
+		return 0, false
+	}
+	stack = stack[len(goroutinePrefix):]
+	if end := bytes.IndexByte(stack, ' '); end >= 0 {
+		stack = stack[:end]
+	}
+	id, err := strconv.ParseInt(string(stack), 10, 64)
+	return id, err == nil
+}
+
+// goroutineStacks returns the stacks of all goroutines by id.
+func goroutineStacks() map[int64]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := map[int64]string{}
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if id, ok := parseGoroutineHeader(stack); ok {
+			stacks[id] = string(bytes.TrimSpace(stack))
+		}
+	}
+	return stacks
+}
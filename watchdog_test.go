@@ -0,0 +1,113 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	r := NewRegistry()
+	mon := r.ScopeNamed("test")
+	wd := r.Watchdog()
+	wd.SetThreshold(time.Millisecond)
+	wd.SetFuncThreshold(mon.FuncNamed("exempt"), -1)
+
+	started, release, finished := make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(finished)
+		ctx := context.Background()
+		defer mon.FuncNamed("blocked").Task(&ctx)(nil)
+		defer mon.FuncNamed("exempt").Task(&ctx)(nil)
+		close(started)
+		<-release
+	}()
+	<-started
+	time.Sleep(5 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		stuck := wd.Check()
+		if len(stuck) != 1 || stuck[0].Span.Func().FullName() != "test.blocked" {
+			t.Fatalf("unexpected stuck spans: %+v", stuck)
+		}
+		if !strings.Contains(stuck[0].Stack, "TestWatchdog") {
+			t.Fatalf("expected goroutine stack, got %q", stuck[0].Stack)
+		}
+	}
+	stats := Collect(r)
+	if stats["function_stuck,name=blocked,scope=test current"] != 1 ||
+		stats["function_stuck,name=blocked,scope=test total"] != 1 {
+		t.Fatalf("unexpected stats: %v", stats)
+	}
+
+	close(release)
+	<-finished
+	if stuck := wd.Check(); len(stuck) != 0 {
+		t.Fatalf("unexpected stuck spans: %+v", stuck)
+	}
+	if Collect(r)["function_stuck,name=blocked,scope=test current"] != 0 {
+		t.Fatal("expected no current stuck spans")
+	}
+}
+
+func TestWatchdogTracking(t *testing.T) {
+	r := NewRegistry()
+	mon := r.ScopeNamed("test")
+	wd := r.Watchdog()
+	tracking := func() bool { return atomic.LoadInt32(&r.trackGoroutines) != 0 }
+
+	wd.SetThreshold(0)
+	wd.SetFuncThreshold(mon.FuncNamed("exempt"), -1)
+	if tracking() {
+		t.Fatal("expected no goroutine tracking without a positive threshold")
+	}
+	wd.SetFuncThreshold(mon.FuncNamed("slow"), time.Minute)
+	if !tracking() {
+		t.Fatal("expected goroutine tracking")
+	}
+	wd.SetFuncThreshold(mon.FuncNamed("slow"), 0)
+	if tracking() {
+		t.Fatal("expected goroutine tracking to stop")
+	}
+}
+
+func TestWatchdogRemovedFunc(t *testing.T) {
+	r := NewRegistry()
+	mon := r.ScopeNamed("test")
+	wd := r.Watchdog()
+	f := mon.FuncNamed("blocked")
+	wd.SetFuncThreshold(f, time.Nanosecond)
+
+	ctx := context.Background()
+	done := f.Task(&ctx)
+	time.Sleep(time.Millisecond)
+	if stuck := wd.Check(); len(stuck) != 1 {
+		t.Fatalf("unexpected stuck spans: %+v", stuck)
+	}
+	done(nil)
+
+	if !mon.RemoveFunc("blocked") {
+		t.Fatal("expected the Func to be removed")
+	}
+	wd.mtx.Lock()
+	defer wd.mtx.Unlock()
+	if len(wd.totals) != 0 || len(wd.thresholds) != 0 {
+		t.Fatalf("expected the removed Func to be forgotten: %v %v", wd.totals, wd.thresholds)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"time"
+
+	"github.com/spacemonkeygo/monkit/v3/monotime"
+)
+
+// WindowedDist keeps a distribution of recently observed values. Where the
+// Low, High, Count and Sum of the other distributions cover everything since
+// construction or the last Reset, a WindowedDist only covers a rolling window
+// of time, split into a ring of slots. Each slot holds a Sketch, so quantiles
+// over the window have bounded relative error. As the current slot is
+// always partially filled, the window covers between slots-1 and slots slot
+// lengths of time.
+//
+// WindowedDist is not threadsafe. It is usually used through the UseWindow
+// method of IntVal, FloatVal and DurationVal, or UseWindows on FuncStats.
+type WindowedDist struct {
+	key        SeriesKey
+	slotLength time.Duration
+	slots      []*Sketch
+	head       int
+	headStart  time.Time
+}
+
+// NewWindowedDist creates a WindowedDist of the given number of slots, each
+// covering slotLength of time. For example, 6 slots of 10 seconds keep about
+// the last minute.
+func NewWindowedDist(key SeriesKey, slots int,
+	slotLength time.Duration) *WindowedDist {
+	w := &WindowedDist{}
+	initWindowedDist(w, key, slots, slotLength)
+	return w
+}
+
+func initWindowedDist(w *WindowedDist, key SeriesKey, slots int,
+	slotLength time.Duration) {
+	if slots < 1 {
+		slots = 1
+	}
+	if slotLength <= 0 {
+		slotLength = time.Second
+	}
+	w.key = key
+	w.slotLength = slotLength
+	w.slots = make([]*Sketch, slots)
+	for i := range w.slots {
+		w.slots[i] = NewSketch(defaultSketchAccuracy)
+	}
+	w.head = 0
+	w.headStart = monotime.Now()
+}
+
+// Insert adds a value to the current slot.
+func (w *WindowedDist) Insert(val float64) {
+	w.insertAt(val, monotime.Now())
+}
+
+func (w *WindowedDist) insertAt(val float64, now time.Time) {
+	w.advance(now)
+	w.slots[w.head].Add(val)
+}
+
+// advance rotates the ring so the head slot contains now, clearing any slots
+// that have fallen out of the window.
+func (w *WindowedDist) advance(now time.Time) {
+	elapsed := now.Sub(w.headStart)
+	if elapsed < w.slotLength {
+		return
+	}
+	n := int64(elapsed / w.slotLength)
+	for i := int64(0); i < n && i < int64(len(w.slots)); i++ {
+		w.head = (w.head + 1) % len(w.slots)
+		w.slots[w.head].Reset()
+	}
+	w.headStart = w.headStart.Add(time.Duration(n) * w.slotLength)
+}
+
+// Window returns a Sketch of the values observed within the window.
+func (w *WindowedDist) Window() *Sketch {
+	return w.windowAt(monotime.Now())
+}
+
+func (w *WindowedDist) windowAt(now time.Time) *Sketch {
+	w.advance(now)
+	rv := NewSketch(defaultSketchAccuracy)
+	for _, slot := range w.slots {
+		_ = rv.Merge(slot)
+	}
+	return rv
+}
+
+// WindowLength returns the total length of time covered by all slots.
+func (w *WindowedDist) WindowLength() time.Duration {
+	return time.Duration(len(w.slots)) * w.slotLength
+}
+
+// Reset clears every slot.
+func (w *WindowedDist) Reset() {
+	for _, slot := range w.slots {
+		slot.Reset()
+	}
+	w.headStart = monotime.Now()
+}
+
+// Copy returns a full copy of the WindowedDist.
+func (w *WindowedDist) Copy() *WindowedDist {
+	c := *w
+	c.slots = make([]*Sketch, len(w.slots))
+	for i, slot := range w.slots {
+		c.slots[i] = slot.Copy()
+	}
+	return &c
+}
+
+// Stats implements the StatSource interface. Fields are prefixed with "w" to
+// set them apart from the all-time fields of the other distributions: wcount,
+// and when anything was observed, wsum, wmin, wmax, wavg, w10, w50, w90 and
+// w99.
+func (w *WindowedDist) Stats(cb func(key SeriesKey, field string, val float64)) {
+	w.windowAt(monotime.Now()).windowStats(w.key, cb)
+}
+
+func (s *Sketch) windowStats(key SeriesKey, cb func(key SeriesKey, field string, val float64)) {
+	cb(key, "wcount", float64(s.Count()))
+	if s.Count() == 0 {
+		return
+	}
+	cb(key, "wsum", s.Sum())
+	cb(key, "wmin", s.Min())
+	cb(key, "wmax", s.Max())
+	cb(key, "wavg", s.Sum()/float64(s.Count()))
+	cb(key, "w10", s.Quantile(.1))
+	cb(key, "w50", s.Quantile(.5))
+	cb(key, "w90", s.Quantile(.9))
+	cb(key, "w99", s.Quantile(.99))
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monkit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowedDist(t *testing.T) {
+	w := NewWindowedDist(NewSeriesKey("w"), 3, time.Second)
+	start := w.headStart
+
+	w.insertAt(100, start)
+	w.insertAt(1, start.Add(1500*time.Millisecond))
+	w.insertAt(2, start.Add(2500*time.Millisecond))
+	if s := w.windowAt(start.Add(2500 * time.Millisecond)); s.Count() != 3 || s.Max() != 100 {
+		t.Fatalf("expected all values in window, got count %d max %v", s.Count(), s.Max())
+	}
+
+	// the first slot falls out of the window.
+	if s := w.windowAt(start.Add(3 * time.Second)); s.Count() != 2 || s.Max() != 2 {
+		t.Fatalf("expected old value to expire, got count %d max %v", s.Count(), s.Max())
+	}
+
+	// everything falls out after a long gap.
+	if s := w.windowAt(start.Add(time.Hour)); s.Count() != 0 {
+		t.Fatalf("expected empty window, got count %d", s.Count())
+	}
+	w.insertAt(5, start.Add(time.Hour))
+	if s := w.windowAt(start.Add(time.Hour + 500*time.Millisecond)); s.Count() != 1 {
+		t.Fatalf("expected one value, got count %d", s.Count())
+	}
+}